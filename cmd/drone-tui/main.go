@@ -6,7 +6,10 @@ import (
 
 	"github.com/arch-err/drone-tui/internal/client"
 	"github.com/arch-err/drone-tui/internal/config"
+	"github.com/arch-err/drone-tui/internal/store"
 	"github.com/arch-err/drone-tui/internal/tui"
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
 	"github.com/arch-err/drone-tui/internal/version"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -24,7 +27,38 @@ func main() {
 	}
 
 	c := client.New(cfg.Server, cfg.Token)
-	m := tui.New(c)
+
+	if len(os.Args) > 1 && os.Args[1] == "sync" {
+		if err := runSync(c); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Keys and themes only matter to the TUI, so sync runs even when they're
+	// broken
+	if err := keymap.Apply(cfg.Keys); err != nil {
+		exitConfigError(err)
+	}
+
+	theme, err := styles.Resolve(cfg.Theme, cfg.Themes)
+	if err != nil {
+		exitConfigError(err)
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		// https://no-color.org
+		theme = styles.NoColor
+	}
+	styles.Apply(theme)
+
+	// A broken state file only loses favorites, so it shouldn't stop startup
+	st, err := store.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	m := tui.New(c, cfg, st)
 
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	if _, err := p.Run(); err != nil {
@@ -32,3 +66,10 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// exitConfigError reports a setting in the config file that can't be used
+func exitConfigError(err error) {
+	path, _ := config.Path()
+	fmt.Fprintf(os.Stderr, "Error: config file %s: %v\n", path, err)
+	os.Exit(1)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/arch-err/drone-tui/internal/client"
+)
+
+// runSync syncs the user's repositories with the remote and prints the
+// repos that became visible
+func runSync(c client.Client) error {
+	before, err := c.ListRepos()
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(before))
+	for _, r := range before {
+		known[r.Slug] = true
+	}
+
+	fmt.Fprintf(os.Stderr, "Syncing repositories with %s...\n", c.ServerURL())
+	after, err := c.SyncRepos()
+	if err != nil {
+		return err
+	}
+
+	var added []string
+	for _, r := range after {
+		if !known[r.Slug] {
+			added = append(added, r.Slug)
+		}
+	}
+	sort.Strings(added)
+	for _, slug := range added {
+		fmt.Printf("+ %s\n", slug)
+	}
+	fmt.Fprintf(os.Stderr, "Synced %d repositories, %d new\n", len(after), len(added))
+	return nil
+}
@@ -0,0 +1,117 @@
+// Package cache keeps finished builds on disk. A finished build never
+// changes, so once fetched in full it can be read back instead of asking
+// the server again.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/arch-err/drone-tui/internal/atomicfile"
+	"github.com/arch-err/drone-tui/internal/status"
+	"github.com/drone/drone-go/drone"
+)
+
+// maxBuilds bounds how many builds are kept per repo; the oldest go first
+const maxBuilds = 500
+
+// Dir returns the cache directory: $DRONE_TUI_CACHE if set, otherwise
+// drone-tui in the user cache directory (~/.cache on Linux)
+func Dir() (string, error) {
+	if dir := os.Getenv("DRONE_TUI_CACHE"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "drone-tui"), nil
+}
+
+// Builds is the cache of one repo's finished builds. It is safe for
+// concurrent use.
+type Builds struct {
+	path   string
+	mu     sync.Mutex
+	builds map[int64]*drone.Build
+	dirty  bool
+}
+
+// LoadBuilds reads the cached builds of the repo slug. A missing or
+// unreadable cache is empty; it's rebuilt as builds are fetched.
+func LoadBuilds(slug string) *Builds {
+	c := &Builds{builds: make(map[int64]*drone.Build)}
+	dir, err := Dir()
+	if err != nil {
+		return c
+	}
+	c.path = filepath.Join(dir, "builds", filepath.FromSlash(slug)+".json")
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return c
+	}
+	var builds []*drone.Build
+	if json.Unmarshal(data, &builds) != nil {
+		return c
+	}
+	for _, b := range builds {
+		c.builds[b.Number] = b
+	}
+	return c
+}
+
+// Get returns the cached build numbered n
+func (c *Builds) Get(n int64) (*drone.Build, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.builds[n]
+	return b, ok
+}
+
+// Put caches b if it has finished
+func (c *Builds) Put(b *drone.Build) {
+	if !status.Finished(b.Status) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.builds[b.Number] = b
+	c.dirty = true
+}
+
+// Save writes the cache if builds were added
+func (c *Builds) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty || c.path == "" {
+		return nil
+	}
+
+	// Newest first, dropping the oldest beyond maxBuilds
+	builds := make([]*drone.Build, 0, len(c.builds))
+	for _, b := range c.builds {
+		builds = append(builds, b)
+	}
+	sort.Slice(builds, func(i, j int) bool { return builds[i].Number > builds[j].Number })
+	if len(builds) > maxBuilds {
+		for _, b := range builds[maxBuilds:] {
+			delete(c.builds, b.Number)
+		}
+		builds = builds[:maxBuilds]
+	}
+
+	data, err := json.Marshal(builds)
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(c.path, data); err != nil {
+		return fmt.Errorf("saving build cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/drone/drone-go/drone"
+)
+
+func TestBuildsRoundTrip(t *testing.T) {
+	t.Setenv("DRONE_TUI_CACHE", t.TempDir())
+
+	c := LoadBuilds("octocat/hello-world")
+	for n := int64(1); n <= maxBuilds+10; n++ {
+		c.Put(&drone.Build{Number: n, Status: "success"})
+	}
+	c.Put(&drone.Build{Number: maxBuilds + 11, Status: "running"})
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	c = LoadBuilds("octocat/hello-world")
+	if _, ok := c.Get(maxBuilds + 10); !ok {
+		t.Error("newest finished build not cached")
+	}
+	if _, ok := c.Get(maxBuilds + 11); ok {
+		t.Error("running build was cached")
+	}
+	if _, ok := c.Get(10); ok {
+		t.Errorf("build #10 kept beyond the %d newest", maxBuilds)
+	}
+	if _, ok := c.Get(11); !ok {
+		t.Error("build #11 dropped, but it is among the newest")
+	}
+
+	if _, ok := LoadBuilds("octocat/other").Get(maxBuilds); ok {
+		t.Error("builds leaked into another repo's cache")
+	}
+}
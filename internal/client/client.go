@@ -1,10 +1,15 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/drone/drone-go/drone"
 	"golang.org/x/oauth2"
@@ -12,12 +17,49 @@ import (
 
 type Client interface {
 	ListRepos() ([]*drone.Repo, error)
-	ListBuilds(namespace, name string, page int) ([]*drone.Build, error)
+	ListIncomplete() ([]*drone.Repo, error)
+	SyncRepos() ([]*drone.Repo, error)
+	ListBuilds(namespace, name string, opts ListOptions) ([]*drone.Build, error)
+	ListBranches(namespace, name string) ([]*drone.Build, error)
+	ListDeployments(namespace, name string) ([]*drone.Build, error)
 	GetBuild(namespace, name string, number int) (*drone.Build, error)
+	GetLastBuild(namespace, name, branch string) (*drone.Build, error)
 	GetLogs(owner, name string, build, stage, step int) ([]*drone.Line, error)
+	ActivateRepo(namespace, name string) (*drone.Repo, error)
+	DeactivateRepo(namespace, name string) error
+	UpdateRepo(namespace, name string, patch *drone.RepoPatch) (*drone.Repo, error)
+	RepairRepo(namespace, name string) error
+	ChownRepo(namespace, name string) (*drone.Repo, error)
+	ListCrons(namespace, name string) ([]*drone.Cron, error)
+	CreateCron(namespace, name string, cron *drone.Cron) (*drone.Cron, error)
+	UpdateCron(namespace, name, cron string, patch *drone.CronPatch) (*drone.Cron, error)
+	DeleteCron(namespace, name, cron string) error
+	RunCron(namespace, name, cron string) (*drone.Build, error)
+	ListSecrets(namespace, name string) ([]*drone.Secret, error)
+	CreateSecret(namespace, name string, secret *drone.Secret) error
+	UpdateSecret(namespace, name, secret string, patch *SecretPatch) error
+	DeleteSecret(namespace, name, secret string) error
 	ServerURL() string
 }
 
+// ListOptions selects a page of builds, optionally restricted to a branch
+type ListOptions struct {
+	Page   int
+	Branch string
+}
+
+// SecretPatch changes an existing secret. Nil fields are left as they are,
+// so the value can be kept while the pull request flags change.
+type SecretPatch struct {
+	Data            *string `json:"data,omitempty"`
+	PullRequest     *bool   `json:"pull_request,omitempty"`
+	PullRequestPush *bool   `json:"pull_request_push,omitempty"`
+}
+
+// fallbackPages limits how many pages of builds are scanned when a server
+// lacks an aggregate endpoint
+const fallbackPages = 5
+
 type droneClient struct {
 	inner      drone.Client
 	httpClient *http.Client
@@ -53,18 +95,284 @@ func (c *droneClient) ListRepos() ([]*drone.Repo, error) {
 	return repos, nil
 }
 
-func (c *droneClient) ListBuilds(namespace, name string, page int) ([]*drone.Build, error) {
-	return c.inner.BuildList(namespace, name, drone.ListOptions{Page: page})
+func (c *droneClient) ListIncomplete() ([]*drone.Repo, error) {
+	return c.inner.Incomplete()
+}
+
+// SyncRepos asks the server to refresh the user's repositories from the
+// remote, which makes newly created repos visible. It blocks until the sync
+// is done.
+func (c *droneClient) SyncRepos() ([]*drone.Repo, error) {
+	return c.inner.RepoListSync()
+}
+
+func (c *droneClient) ListBuilds(namespace, name string, opts ListOptions) ([]*drone.Build, error) {
+	if opts.Branch == "" {
+		return c.inner.BuildList(namespace, name, drone.ListOptions{Page: opts.Page})
+	}
+
+	// The SDK has no branch option, but the API filters on it server-side
+	params := url.Values{}
+	params.Set("branch", opts.Branch)
+	if opts.Page != 0 {
+		params.Set("page", strconv.Itoa(opts.Page))
+	}
+	uri := fmt.Sprintf("%s/api/repos/%s/%s/builds?%s", c.server, namespace, name, params.Encode())
+	var builds []*drone.Build
+	if err := c.getJSON(uri, &builds); err != nil {
+		return nil, err
+	}
+	return builds, nil
+}
+
+// ListBranches returns the latest build of every branch. Servers without
+// the branches endpoint fall back to grouping the most recent builds.
+func (c *droneClient) ListBranches(namespace, name string) ([]*drone.Build, error) {
+	uri := fmt.Sprintf("%s/api/repos/%s/%s/builds/branches", c.server, namespace, name)
+	var latest []*drone.Build
+	err := c.getJSON(uri, &latest)
+	if err == nil {
+		return latest, nil
+	}
+	if !isNotFound(err) {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for page := 1; page <= fallbackPages; page++ {
+		builds, err := c.inner.BuildList(namespace, name, drone.ListOptions{Page: page})
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range builds {
+			// Pull requests and deployments report the base branch as
+			// target, only pushes say where the branch itself is
+			if b.Event != "push" || seen[b.Target] {
+				continue
+			}
+			seen[b.Target] = true
+			latest = append(latest, b)
+		}
+		if len(builds) == 0 {
+			break
+		}
+	}
+	return latest, nil
+}
+
+// ListDeployments returns the latest deployment build of every target
+// environment. Servers without the deployments endpoint fall back to
+// scanning the most recent builds.
+func (c *droneClient) ListDeployments(namespace, name string) ([]*drone.Build, error) {
+	uri := fmt.Sprintf("%s/api/repos/%s/%s/builds/deployments", c.server, namespace, name)
+	var latest []*drone.Build
+	err := c.getJSON(uri, &latest)
+	if err == nil {
+		return latest, nil
+	}
+	if !isNotFound(err) {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for page := 1; page <= fallbackPages; page++ {
+		builds, err := c.inner.BuildList(namespace, name, drone.ListOptions{Page: page})
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range builds {
+			if b.Deploy == "" || seen[b.Deploy] {
+				continue
+			}
+			seen[b.Deploy] = true
+			latest = append(latest, b)
+		}
+		if len(builds) == 0 {
+			break
+		}
+	}
+	return latest, nil
 }
 
 func (c *droneClient) GetBuild(namespace, name string, number int) (*drone.Build, error) {
 	return c.inner.Build(namespace, name, number)
 }
 
+func (c *droneClient) GetLastBuild(namespace, name, branch string) (*drone.Build, error) {
+	return c.inner.BuildLast(namespace, name, branch)
+}
+
 func (c *droneClient) GetLogs(owner, name string, build, stage, step int) ([]*drone.Line, error) {
 	return c.inner.Logs(owner, name, build, stage, step)
 }
 
+func (c *droneClient) ActivateRepo(namespace, name string) (*drone.Repo, error) {
+	return c.inner.RepoEnable(namespace, name)
+}
+
+func (c *droneClient) DeactivateRepo(namespace, name string) error {
+	return c.inner.RepoDisable(namespace, name)
+}
+
+func (c *droneClient) UpdateRepo(namespace, name string, patch *drone.RepoPatch) (*drone.Repo, error) {
+	return c.inner.RepoUpdate(namespace, name, patch)
+}
+
+// RepairRepo recreates the repository's webhook on the remote
+func (c *droneClient) RepairRepo(namespace, name string) error {
+	return c.inner.RepoRepair(namespace, name)
+}
+
+// ChownRepo makes the current user the repository owner, whose credentials
+// Drone uses to talk to the remote
+func (c *droneClient) ChownRepo(namespace, name string) (*drone.Repo, error) {
+	return c.inner.RepoChown(namespace, name)
+}
+
+func (c *droneClient) ListCrons(namespace, name string) ([]*drone.Cron, error) {
+	return c.inner.CronList(namespace, name)
+}
+
+func (c *droneClient) CreateCron(namespace, name string, cron *drone.Cron) (*drone.Cron, error) {
+	return c.inner.CronCreate(namespace, name, cron)
+}
+
+func (c *droneClient) UpdateCron(namespace, name, cron string, patch *drone.CronPatch) (*drone.Cron, error) {
+	return c.inner.CronUpdate(namespace, name, cron, patch)
+}
+
+func (c *droneClient) DeleteCron(namespace, name, cron string) error {
+	return c.inner.CronDelete(namespace, name, cron)
+}
+
+// RunCron triggers the cron job now and returns the build it created.
+// Servers that don't return the build are asked for the newest build of the
+// cron instead.
+func (c *droneClient) RunCron(namespace, name, cron string) (*drone.Build, error) {
+	uri := fmt.Sprintf("%s/api/repos/%s/%s/cron/%s", c.server, namespace, name, url.PathEscape(cron))
+	build := new(drone.Build)
+	if err := c.sendJSON(http.MethodPost, uri, nil, build); err != nil {
+		return nil, err
+	}
+	if build.Number != 0 {
+		return build, nil
+	}
+
+	builds, err := c.inner.BuildList(namespace, name, drone.ListOptions{Page: 1})
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range builds {
+		if b.Event == "cron" && b.Cron == cron {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("cron %s started but its build was not found", cron)
+}
+
+// The secret methods address a repo's secrets, or the organization secrets
+// of namespace when name is empty. Secret values are write-only: the server
+// never returns them.
+
+func (c *droneClient) ListSecrets(namespace, name string) ([]*drone.Secret, error) {
+	if name == "" {
+		return c.inner.OrgSecretList(namespace)
+	}
+	return c.inner.SecretList(namespace, name)
+}
+
+func (c *droneClient) CreateSecret(namespace, name string, secret *drone.Secret) error {
+	var err error
+	if name == "" {
+		_, err = c.inner.OrgSecretCreate(namespace, secret)
+	} else {
+		_, err = c.inner.SecretCreate(namespace, name, secret)
+	}
+	return err
+}
+
+// UpdateSecret sends its own patch because drone.Secret drops false flags,
+// which would make exposure to pull requests impossible to turn off
+func (c *droneClient) UpdateSecret(namespace, name, secret string, patch *SecretPatch) error {
+	uri := fmt.Sprintf("%s/api/repos/%s/%s/secrets/%s", c.server, namespace, name, url.PathEscape(secret))
+	if name == "" {
+		uri = fmt.Sprintf("%s/api/secrets/%s/%s", c.server, namespace, url.PathEscape(secret))
+	}
+	return c.sendJSON(http.MethodPatch, uri, patch, nil)
+}
+
+func (c *droneClient) DeleteSecret(namespace, name, secret string) error {
+	if name == "" {
+		return c.inner.OrgSecretDelete(namespace, secret)
+	}
+	return c.inner.SecretDelete(namespace, name, secret)
+}
+
 func (c *droneClient) ServerURL() string {
 	return c.server
 }
+
+// getJSON fetches uri and decodes the JSON response into out
+func (c *droneClient) getJSON(uri string, out interface{}) error {
+	resp, err := c.httpClient.Get(uri)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return decodeError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// sendJSON sends in, if any, as JSON to uri and decodes the JSON response,
+// if any, into out
+func (c *droneClient) sendJSON(method, uri string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		buf, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequest(method, uri, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// decodeError turns a failed response into the server's error message
+func decodeError(resp *http.Response) error {
+	apiErr := new(drone.Error)
+	if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil || apiErr.Message == "" {
+		apiErr.Message = fmt.Sprintf("client error %d", resp.StatusCode)
+	}
+	apiErr.Code = resp.StatusCode
+	return apiErr
+}
+
+// isNotFound reports whether err is the server saying an endpoint or
+// resource doesn't exist
+func isNotFound(err error) bool {
+	var apiErr *drone.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
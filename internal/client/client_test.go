@@ -0,0 +1,96 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone-go/drone"
+)
+
+// server serves the given JSON bodies by path; other paths get status
+func server(t *testing.T, status int, routes map[string]any) Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(drone.Error{Message: http.StatusText(status)})
+			return
+		}
+		if page := r.URL.Query().Get("page"); page != "" && page != "1" {
+			body = []*drone.Build{}
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	return New(srv.URL, "token")
+}
+
+func TestListBranches(t *testing.T) {
+	c := server(t, http.StatusNotFound, map[string]any{
+		"/api/repos/octocat/hello-world/builds/branches": []*drone.Build{{Number: 2, Target: "main"}},
+	})
+	builds, err := c.ListBranches("octocat", "hello-world")
+	if err != nil || len(builds) != 1 || builds[0].Number != 2 {
+		t.Errorf("ListBranches() = %v, %v, want #2 from the branches endpoint", builds, err)
+	}
+}
+
+func TestListBranchesFallback(t *testing.T) {
+	c := server(t, http.StatusNotFound, map[string]any{
+		"/api/repos/octocat/hello-world/builds": []*drone.Build{
+			{Number: 4, Event: "pull_request", Target: "main"},
+			{Number: 3, Event: "push", Target: "main"},
+			{Number: 2, Event: "push", Target: "dev"},
+			{Number: 1, Event: "push", Target: "main"},
+		},
+	})
+	builds, err := c.ListBranches("octocat", "hello-world")
+	if err != nil || len(builds) != 2 || builds[0].Number != 3 || builds[1].Number != 2 {
+		t.Errorf("ListBranches() = %v, %v, want #3 and #2 from the build list", builds, err)
+	}
+}
+
+func TestListBranchesError(t *testing.T) {
+	c := server(t, http.StatusUnauthorized, map[string]any{
+		"/api/repos/octocat/hello-world/builds": []*drone.Build{{Number: 1, Event: "push", Target: "main"}},
+	})
+	if builds, err := c.ListBranches("octocat", "hello-world"); err == nil {
+		t.Errorf("ListBranches() = %v, want the 401 reported", builds)
+	}
+}
+
+func TestListDeployments(t *testing.T) {
+	c := server(t, http.StatusNotFound, map[string]any{
+		"/api/repos/octocat/hello-world/builds/deployments": []*drone.Build{{Number: 5, Deploy: "production"}},
+	})
+	builds, err := c.ListDeployments("octocat", "hello-world")
+	if err != nil || len(builds) != 1 || builds[0].Number != 5 {
+		t.Errorf("ListDeployments() = %v, %v, want #5 from the deployments endpoint", builds, err)
+	}
+}
+
+func TestListDeploymentsFallback(t *testing.T) {
+	c := server(t, http.StatusNotFound, map[string]any{
+		"/api/repos/octocat/hello-world/builds": []*drone.Build{
+			{Number: 3, Deploy: "staging"},
+			{Number: 2, Event: "push"},
+			{Number: 1, Deploy: "staging"},
+		},
+	})
+	builds, err := c.ListDeployments("octocat", "hello-world")
+	if err != nil || len(builds) != 1 || builds[0].Number != 3 {
+		t.Errorf("ListDeployments() = %v, %v, want #3 from the build list", builds, err)
+	}
+}
+
+func TestListDeploymentsError(t *testing.T) {
+	c := server(t, http.StatusInternalServerError, map[string]any{
+		"/api/repos/octocat/hello-world/builds": []*drone.Build{{Number: 1, Deploy: "staging"}},
+	})
+	if builds, err := c.ListDeployments("octocat", "hello-world"); err == nil {
+		t.Errorf("ListDeployments() = %v, want the 500 reported", builds)
+	}
+}
@@ -1,25 +1,151 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Server string
-	Token  string
+	Server  string  `yaml:"server"`
+	Token   string  `yaml:"token"`
+	Refresh Refresh `yaml:"refresh"`
+	Notify  Notify  `yaml:",inline"`
+	// Keys overrides key bindings by action name
+	Keys Keys `yaml:"keys"`
+	// Theme names a built-in theme or one of Themes
+	Theme string `yaml:"theme"`
+	// Themes defines custom themes as color role to color
+	Themes map[string]map[string]string `yaml:"themes"`
+	// Split starts the build list with a log preview beside it on wide
+	// terminals
+	Split bool `yaml:"split"`
+}
+
+// Refresh controls background auto-refresh of the repo and build lists
+type Refresh struct {
+	// Interval between refreshes when nothing is running. Zero disables
+	// auto-refresh.
+	Interval time.Duration `yaml:"interval"`
+	// ActiveInterval is used instead while any listed build is running or
+	// pending
+	ActiveInterval time.Duration `yaml:"active_interval"`
+}
+
+// Notify controls how finished watched builds are announced
+type Notify struct {
+	// Bell rings the terminal bell
+	Bell bool `yaml:"notify_bell"`
+	// Escape selects a desktop notification escape sequence: "osc9",
+	// "osc777" or empty for none
+	Escape string `yaml:"notify_escape"`
+	// Command is run through the shell with DRONE_TUI_NOTIFY_* variables set
+	Command string `yaml:"notify_command"`
+}
+
+// Keys maps action names to the keys bound to them. A single key may be
+// given as a plain string instead of a list.
+type Keys map[string][]string
+
+func (k *Keys) UnmarshalYAML(value *yaml.Node) error {
+	var raw map[string]yaml.Node
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	*k = make(Keys, len(raw))
+	for action, node := range raw {
+		keys := []string{}
+		switch {
+		case node.Tag == "!!null":
+			// Left empty to unbind the action
+		case node.Kind == yaml.ScalarNode:
+			keys = []string{node.Value}
+		default:
+			if err := node.Decode(&keys); err != nil {
+				return fmt.Errorf("keys: %s: %w", action, err)
+			}
+		}
+		(*k)[action] = keys
+	}
+	return nil
+}
+
+func defaults() Config {
+	return Config{
+		Refresh: Refresh{
+			Interval:       30 * time.Second,
+			ActiveInterval: 5 * time.Second,
+		},
+		Notify: Notify{
+			Bell: true,
+		},
+	}
 }
 
 func Load() (Config, error) {
-	server := os.Getenv("DRONE_SERVER")
-	if server == "" {
+	cfg := defaults()
+	if err := loadFile(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	// Environment variables take precedence over the config file
+	if server := os.Getenv("DRONE_SERVER"); server != "" {
+		cfg.Server = server
+	}
+	if cfg.Server == "" {
 		return Config{}, fmt.Errorf("DRONE_SERVER environment variable is not set")
 	}
 
-	token := os.Getenv("DRONE_TOKEN")
-	if token == "" {
+	if token := os.Getenv("DRONE_TOKEN"); token != "" {
+		cfg.Token = token
+	}
+	if cfg.Token == "" {
 		return Config{}, fmt.Errorf("DRONE_TOKEN environment variable is not set")
 	}
 
-	return Config{Server: server, Token: token}, nil
+	return cfg, nil
+}
+
+// Path returns the config file location: $DRONE_TUI_CONFIG if set,
+// otherwise drone-tui/config.yaml in the user config directory
+func Path() (string, error) {
+	if p := os.Getenv("DRONE_TUI_CONFIG"); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "drone-tui", "config.yaml"), nil
+}
+
+// loadFile overlays the config file onto cfg. A missing file is not an error.
+func loadFile(cfg *Config) error {
+	path, err := Path()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	switch cfg.Notify.Escape {
+	case "", "osc9", "osc777":
+	default:
+		return fmt.Errorf("config file %s: notify_escape must be osc9, osc777 or empty, got %q", path, cfg.Notify.Escape)
+	}
+	if cfg.Refresh.ActiveInterval <= 0 || cfg.Refresh.ActiveInterval > cfg.Refresh.Interval {
+		cfg.Refresh.ActiveInterval = cfg.Refresh.Interval
+	}
+	return nil
 }
@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/config"
+)
+
+// Escape sequence flavours understood by terminals for desktop notifications
+const (
+	EscapeNone   = ""
+	EscapeOSC9   = "osc9"
+	EscapeOSC777 = "osc777"
+)
+
+// Notification describes a finished watched build
+type Notification struct {
+	Title  string
+	Body   string
+	Status string
+	URL    string
+}
+
+// Notifier delivers notifications through the terminal and an optional
+// external command. Terminal output goes to out so it can be captured.
+type Notifier struct {
+	out     io.Writer
+	bell    bool
+	escape  string
+	command string
+}
+
+func New(cfg config.Notify, out io.Writer) *Notifier {
+	return &Notifier{
+		out:     out,
+		bell:    cfg.Bell,
+		escape:  cfg.Escape,
+		command: cfg.Command,
+	}
+}
+
+// Send writes the terminal sequences in a single write, so they can't
+// interleave with a frame being rendered, then runs the notify command
+func (n *Notifier) Send(note Notification) error {
+	if seq := Sequence(n.escape, n.bell, note); seq != "" {
+		if _, err := io.WriteString(n.out, seq); err != nil {
+			return err
+		}
+	}
+	if n.command == "" {
+		return nil
+	}
+	return n.run(note)
+}
+
+// Sequence returns the terminal escape sequences for note
+func Sequence(escape string, bell bool, note Notification) string {
+	var sb strings.Builder
+	switch escape {
+	case EscapeOSC9:
+		// iTerm2, WezTerm, Windows Terminal, kitty
+		fmt.Fprintf(&sb, "\x1b]9;%s: %s\x07", sanitize(note.Title), sanitize(note.Body))
+	case EscapeOSC777:
+		// urxvt, foot, Ghostty, VTE based terminals
+		fmt.Fprintf(&sb, "\x1b]777;notify;%s;%s\x07", sanitize(note.Title), sanitize(note.Body))
+	}
+	if bell {
+		sb.WriteString("\a")
+	}
+	return sb.String()
+}
+
+// run executes the notify command through the shell with the notification
+// passed in environment variables
+func (n *Notifier) run(note Notification) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", n.command)
+	} else {
+		cmd = exec.Command("sh", "-c", n.command)
+	}
+	cmd.Env = append(os.Environ(),
+		"DRONE_TUI_NOTIFY_TITLE="+note.Title,
+		"DRONE_TUI_NOTIFY_BODY="+note.Body,
+		"DRONE_TUI_NOTIFY_STATUS="+note.Status,
+		"DRONE_TUI_NOTIFY_URL="+note.URL,
+	)
+	return cmd.Run()
+}
+
+// sanitize strips characters that would terminate or corrupt an OSC payload
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\x07' || r == '\x1b' || r == ';':
+			return ' '
+		case r < 0x20:
+			return ' '
+		}
+		return r
+	}, s)
+}
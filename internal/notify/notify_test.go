@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/arch-err/drone-tui/internal/config"
+)
+
+var note = Notification{
+	Title:  "octocat/hello-world #42 failure",
+	Body:   "Fix the build",
+	Status: "failure",
+	URL:    "https://drone.example.com/octocat/hello-world/42",
+}
+
+func TestSequence(t *testing.T) {
+	tests := []struct {
+		name   string
+		escape string
+		bell   bool
+		want   string
+	}{
+		{"nothing", EscapeNone, false, ""},
+		{"bell only", EscapeNone, true, "\a"},
+		{"osc9", EscapeOSC9, false, "\x1b]9;octocat/hello-world #42 failure: Fix the build\x07"},
+		{"osc777 with bell", EscapeOSC777, true, "\x1b]777;notify;octocat/hello-world #42 failure;Fix the build\x07\a"},
+		{"unknown escape", "osc42", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sequence(tt.escape, tt.bell, note); got != tt.want {
+				t.Errorf("Sequence() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"semi;colon", "semi colon"},
+		{"bell\x07 and esc\x1b]0;title", "bell  and esc ]0 title"},
+		{"line\nbreak\ttab", "line break tab"},
+		{"ünïcödé ✓", "ünïcödé ✓"},
+	}
+	for _, tt := range tests {
+		if got := sanitize(tt.in); got != tt.want {
+			t.Errorf("sanitize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	var out bytes.Buffer
+	n := New(config.Notify{Bell: true, Escape: EscapeOSC9}, &out)
+	if err := n.Send(note); err != nil {
+		t.Fatal(err)
+	}
+	want := Sequence(EscapeOSC9, true, note)
+	if out.String() != want {
+		t.Errorf("wrote %q, want %q", out.String(), want)
+	}
+}
+
+func TestSendCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("notify command test uses sh")
+	}
+	path := filepath.Join(t.TempDir(), "note")
+	var out bytes.Buffer
+	n := New(config.Notify{Command: `printf '%s|%s' "$DRONE_TUI_NOTIFY_STATUS" "$DRONE_TUI_NOTIFY_URL" > ` + path}, &out)
+	if err := n.Send(note); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("wrote %q to the terminal, want nothing", out.String())
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := note.Status + "|" + note.URL; string(got) != want {
+		t.Errorf("command saw %q, want %q", got, want)
+	}
+}
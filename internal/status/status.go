@@ -0,0 +1,12 @@
+// Package status interprets Drone build and step statuses.
+package status
+
+// Finished reports whether a build or step with this status is done and
+// won't change anymore
+func Finished(status string) bool {
+	switch status {
+	case "success", "failure", "error", "killed", "declined", "skipped":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package status
+
+import "testing"
+
+func TestFinished(t *testing.T) {
+	for status, want := range map[string]bool{
+		"success":  true,
+		"failure":  true,
+		"error":    true,
+		"killed":   true,
+		"declined": true,
+		"skipped":  true,
+		"pending":  false,
+		"running":  false,
+		"blocked":  false,
+		"waiting":  false,
+	} {
+		if got := Finished(status); got != want {
+			t.Errorf("Finished(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
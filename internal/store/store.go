@@ -0,0 +1,80 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/arch-err/drone-tui/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// State is what drone-tui remembers between sessions, as opposed to the
+// config file the user edits
+type State struct {
+	// Favorites holds the slugs of starred repos
+	Favorites []string `yaml:"favorites"`
+}
+
+// Path returns the state file location: $DRONE_TUI_STATE if set, otherwise
+// drone-tui/state.yaml in $XDG_STATE_HOME (~/.local/state) on Unix and in
+// the user config directory elsewhere
+func Path() (string, error) {
+	if p := os.Getenv("DRONE_TUI_STATE"); p != "" {
+		return p, nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "drone-tui", "state.yaml"), nil
+	}
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".local", "state", "drone-tui", "state.yaml"), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "drone-tui", "state.yaml"), nil
+}
+
+// Load reads the state file. A missing file is an empty state.
+func Load() (State, error) {
+	var s State
+	path, err := Path()
+	if err != nil {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("reading state file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return State{}, fmt.Errorf("parsing state file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes the state file, replacing it atomically so a crash can't
+// leave it half written
+func (s State) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return fmt.Errorf("saving state: %w", err)
+	}
+	return nil
+}
@@ -2,18 +2,38 @@ package tui
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/arch-err/drone-tui/internal/client"
+	"github.com/arch-err/drone-tui/internal/config"
+	"github.com/arch-err/drone-tui/internal/notify"
+	"github.com/arch-err/drone-tui/internal/status"
+	"github.com/arch-err/drone-tui/internal/store"
+	"github.com/arch-err/drone-tui/internal/tui/branches"
+	"github.com/arch-err/drone-tui/internal/tui/buildinfo"
+	"github.com/arch-err/drone-tui/internal/tui/buildquery"
 	"github.com/arch-err/drone-tui/internal/tui/builds"
+	"github.com/arch-err/drone-tui/internal/tui/compare"
+	"github.com/arch-err/drone-tui/internal/tui/crons"
+	"github.com/arch-err/drone-tui/internal/tui/dashboard"
+	"github.com/arch-err/drone-tui/internal/tui/deployments"
+	"github.com/arch-err/drone-tui/internal/tui/help"
+	"github.com/arch-err/drone-tui/internal/tui/insights"
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
 	"github.com/arch-err/drone-tui/internal/tui/logs"
 	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/palette"
+	"github.com/arch-err/drone-tui/internal/tui/pipeline"
 	"github.com/arch-err/drone-tui/internal/tui/repos"
+	"github.com/arch-err/drone-tui/internal/tui/secrets"
+	"github.com/arch-err/drone-tui/internal/tui/settings"
 	"github.com/arch-err/drone-tui/internal/tui/styles"
-	"github.com/charmbracelet/bubbles/key"
+	"github.com/arch-err/drone-tui/internal/tui/timeline"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -29,39 +49,129 @@ const (
 	stateBuildList
 	stateLoadingBuild
 	stateLogViewer
+	stateBuildInfo
+	statePipeline
+	stateTimeline
+	stateDashboard
+	stateBranchList
+	stateDeployments
+	stateCrons
+	stateSecrets
+	stateSettings
+	stateCompare
+	stateInsights
 )
 
 type Model struct {
 	state            state
 	client           client.Client
+	refresh          config.Refresh
+	notifier         *notify.Notifier
+	store            store.State
 	spinner          spinner.Model
 	width            int
 	height           int
 	err              error
 	isRefreshing     bool
+	isSyncing        bool
 	loadingStartTime time.Time
 
-	repoList  repos.Model
-	buildList builds.Model
-	logViewer logs.Model
+	repoList    repos.Model
+	buildList   builds.Model
+	logViewer   logs.Model
+	buildInfo   buildinfo.Model
+	pipeline    pipeline.Model
+	timeline    timeline.Model
+	comparison  compare.Model
+	insights    insights.Model
+	dashboard   dashboard.Model
+	branchList  branches.Model
+	deployments deployments.Model
+	crons       crons.Model
+	secrets     secrets.Model
+	settings    settings.Model
+
+	// Key binding overlay, drawn over the current state while open
+	helpView help.Model
+	showHelp bool
+
+	// Command palette, drawn over the current state while open
+	palette     palette.Model
+	showPalette bool
+
+	// Split build list: a log preview of the highlighted build beside the
+	// list on wide terminals. The preview is reloaded per generation, so
+	// builds the cursor only passed over are never fetched.
+	split          bool
+	previewFocused bool
+	preview        logs.Model
+	previewRepo    string
+	previewNumber  int64
+	previewLoaded  bool
+	previewErr     error
+	previewGen     int
+
+	// Pages behind and ahead of the current one, for back and forward
+	history []page
+	forward []page
+
+	// Generation of the dashboard auto-refresh loop; ticks from older
+	// generations are dropped
+	dashboardGen int
+	// Failed builds found by the last per-repo dashboard scan
+	dashboardScanned   []*drone.Repo
+	dashboardScannedAt time.Time
 
 	selectedRepo  *drone.Repo
 	selectedBuild *drone.Build
 
+	// Structured build filter for the selected repo, and the repo and
+	// server-side branch the current build list was loaded with
+	buildQuery      buildquery.Query
+	buildListRepo   string
+	buildListBranch string
+
 	// Pending data waiting for minimum loading time
 	pendingRepos  []*drone.Repo
 	pendingBuilds []*drone.Build
 	pendingBuild  *drone.Build
 
-	// Track pending 'g' for vim-style gx binding
-	pendingG bool
+	// The two most recent keys, for matching sequences like gx
+	prevKey string
+	lastKey string
+
+	// Watched builds and branches, polled in the background
+	watches      []watch
+	watchPolling bool
+
+	// Short-lived statusbar message
+	flash    string
+	flashGen int
 }
 
-const minLoadingDuration = 500 * time.Millisecond
+const (
+	minLoadingDuration = 500 * time.Millisecond
+	// Polling interval for the dashboard and watches when auto-refresh is
+	// disabled in the config
+	defaultActiveInterval = 10 * time.Second
+	// dashboardWorkers bounds how many repos' builds are listed at once for
+	// the dashboard
+	dashboardWorkers = 8
+	// failureScanInterval is how often the dashboard lists every active
+	// repo's builds for failures behind a newer build, as that costs a
+	// request per repo
+	failureScanInterval = 5 * time.Minute
+)
 
 type loadingCompleteMsg struct{}
 
-func New(c client.Client) Model {
+type autoRefreshMsg struct{}
+
+type dashboardTickMsg struct {
+	gen int
+}
+
+func New(c client.Client, cfg config.Config, st store.State) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = styles.SpinnerStyle
@@ -69,13 +179,17 @@ func New(c client.Client) Model {
 	return Model{
 		state:            stateLoadingRepos,
 		client:           c,
+		refresh:          cfg.Refresh,
+		notifier:         notify.New(cfg.Notify, os.Stderr),
+		store:            st,
 		spinner:          s,
 		loadingStartTime: time.Now(),
+		split:            cfg.Split,
 	}
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, m.loadReposCmd())
+	return tea.Batch(m.spinner.Tick, m.loadReposCmd(), m.autoRefreshTickCmd())
 }
 
 func (m Model) Update(teaMsg tea.Msg) (tea.Model, tea.Cmd) {
@@ -83,37 +197,98 @@ func (m Model) Update(teaMsg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = teaMsg.Width
 		m.height = teaMsg.Height
-		return m.propagateSize(), nil
+		m.helpView.SetSize(m.width, m.height)
+		m.palette.SetSize(m.width, m.height)
+		if !m.splitActive() {
+			m.previewFocused = false
+			m.preview.SetFocused(false)
+		}
+		m = m.propagateSize()
+		return m.schedulePreview()
 
-	case tea.KeyMsg:
-		if key.Matches(teaMsg, keys.Quit) {
-			if m.state == stateRepoList && m.repoList.IsFiltering() {
-				break
-			}
-			if m.state == stateBuildList && m.buildList.IsFiltering() {
-				break
+	case tea.MouseMsg:
+		if m.showHelp {
+			var cmd tea.Cmd
+			m.helpView, cmd = m.helpView.Update(teaMsg)
+			return m, cmd
+		}
+		if teaMsg.Y == 0 && teaMsg.Action == tea.MouseActionPress && teaMsg.Button == tea.MouseButtonLeft &&
+			isPage(m.state) && m.state != stateRepoList && !m.showPalette {
+			if m, cmd, ok := m.clickBreadcrumb(teaMsg.X); ok {
+				return m, cmd
 			}
+		}
+
+	case tea.KeyMsg:
+		m.prevKey, m.lastKey = m.lastKey, teaMsg.String()
+		if m.showHelp {
+			return m.updateHelp(teaMsg)
+		}
+		if m.showPalette {
+			return m.updatePalette(teaMsg)
+		}
+		if m.isTyping() {
+			// Keys typed into a filter or form never start a sequence
+			m.lastKey = ""
+			break
+		}
+
+		if keymap.Matches(teaMsg, m.prevKey, keymap.Quit) {
 			return m, tea.Quit
 		}
 
-		// Refresh keybind
-		if teaMsg.String() == "r" {
-			m.pendingG = false
+		if keymap.Matches(teaMsg, m.prevKey, keymap.ShowHelp) {
+			m.lastKey = ""
+			m.helpView = help.New(m.scope(), m.width, m.height)
+			m.showHelp = true
+			return m, nil
+		}
+
+		if keymap.Matches(teaMsg, m.prevKey, keymap.OpenPalette) && m.scope() != "" {
+			m.lastKey = ""
+			m.palette = palette.New(m.paletteCommands(), m.width, m.height)
+			m.showPalette = true
+			return m, nil
+		}
+
+		if keymap.Matches(teaMsg, m.prevKey, keymap.HistoryBack) && isPage(m.state) {
+			m.lastKey = ""
+			if len(m.history) == 0 {
+				return m, nil
+			}
+			return m.back()
+		}
+
+		if keymap.Matches(teaMsg, m.prevKey, keymap.HistoryForward) && isPage(m.state) {
+			m.lastKey = ""
+			return m.goForward()
+		}
+
+		if keymap.Matches(teaMsg, m.prevKey, keymap.Refresh) {
+			m.lastKey = ""
 			switch m.state {
 			case stateRepoList:
-				if !m.repoList.IsFiltering() {
-					m.state = stateLoadingRepos
-					m.isRefreshing = true
-					m.loadingStartTime = time.Now()
-					return m, tea.Batch(m.spinner.Tick, m.loadReposCmd())
-				}
+				m.state = stateLoadingRepos
+				m.isRefreshing = true
+				m.loadingStartTime = time.Now()
+				return m, tea.Batch(m.spinner.Tick, m.loadReposCmd())
 			case stateBuildList:
-				if !m.buildList.IsFiltering() {
-					m.state = stateLoadingBuilds
-					m.isRefreshing = true
-					m.loadingStartTime = time.Now()
-					return m, tea.Batch(m.spinner.Tick, m.loadBuildsCmd(m.selectedRepo.Namespace, m.selectedRepo.Name))
-				}
+				m.state = stateLoadingBuilds
+				m.isRefreshing = true
+				m.loadingStartTime = time.Now()
+				return m, tea.Batch(m.spinner.Tick, m.loadBuildsCmd(m.selectedRepo.Namespace, m.selectedRepo.Name))
+			case stateDashboard:
+				return m, m.loadDashboardCmd(true)
+			case stateBranchList:
+				return m.openRepoTab(stateBranchList)
+			case stateDeployments:
+				return m, m.loadDeploymentsCmd()
+			case stateCrons:
+				return m, m.loadCronsCmd()
+			case stateSecrets:
+				return m, m.loadSecretsCmd(m.secrets.Org())
+			case stateInsights:
+				return m.openRepoTab(stateInsights)
 			case stateLogViewer:
 				m.state = stateLoadingBuild
 				m.isRefreshing = true
@@ -122,18 +297,8 @@ func (m Model) Update(teaMsg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		// Vim-style gx to open in browser
-		if teaMsg.String() == "g" {
-			// Don't interfere when filtering
-			if (m.state == stateRepoList && m.repoList.IsFiltering()) ||
-				(m.state == stateBuildList && m.buildList.IsFiltering()) {
-				m.pendingG = false
-				break
-			}
-			m.pendingG = true
-			// Don't return - let child views also handle 'g' for gg binding
-		} else if teaMsg.String() == "x" && m.pendingG {
-			m.pendingG = false
+		if keymap.Matches(teaMsg, m.prevKey, keymap.OpenInBrowser) {
+			m.lastKey = ""
 			url := m.buildCurrentURL()
 			if url != "" {
 				return m, func() tea.Msg {
@@ -141,11 +306,38 @@ func (m Model) Update(teaMsg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			return m, nil
-		} else {
-			// Reset pending g on any other key (except g itself)
-			m.pendingG = false
 		}
 
+	case previewTickMsg:
+		if teaMsg.gen != m.previewGen {
+			return m, nil
+		}
+		return m, m.loadPreviewCmd()
+
+	case previewLoadedMsg:
+		if teaMsg.gen != m.previewGen {
+			return m, nil
+		}
+		if teaMsg.err != nil {
+			m.previewErr = teaMsg.err
+			return m, nil
+		}
+		return m.newPreview(teaMsg.build)
+
+	case msg.LogsLoadedMsg:
+		// Logs of the previewed build; the log viewer gets them below
+		if m.previewLoaded {
+			m.preview, _ = m.preview.Update(teaMsg)
+		}
+		m = m.deliverLogs(teaMsg)
+
+	case msg.StepSelectedMsg:
+		// The pipeline graph was opened from the log viewer
+		var cmd tea.Cmd
+		m, cmd = m.back()
+		m.logViewer.SelectStep(teaMsg.StageNum, teaMsg.StepNum)
+		return m, cmd
+
 	case msg.OpenBrowserMsg:
 		openBrowser(teaMsg.URL)
 		return m, nil
@@ -162,13 +354,44 @@ func (m Model) Update(teaMsg tea.Msg) (tea.Model, tea.Cmd) {
 				return loadingCompleteMsg{}
 			})
 		}
-		m.repoList = repos.New(teaMsg.Repos, m.width, m.height)
+		m.repoList = repos.New(teaMsg.Repos, m.store.Favorites, m.width, m.repoListHeight())
 		m.state = stateRepoList
 		m.isRefreshing = false
 		return m, nil
 
+	case msg.ReposSyncedMsg:
+		m.state = stateRepoList
+		m.isRefreshing = false
+		m.isSyncing = false
+		m.repoList.SetSize(m.width, m.repoListHeight())
+		if teaMsg.Err != nil {
+			return m.setFlash("Sync failed: " + teaMsg.Err.Error())
+		}
+		added := newRepoCount(m.repoList.Repos(), teaMsg.Repos)
+		listCmd := m.repoList.Merge(teaMsg.Repos)
+		text := "Synced, no new repositories"
+		if added == 1 {
+			text = "Synced, 1 new repository"
+		} else if added > 1 {
+			text = fmt.Sprintf("Synced, %d new repositories", added)
+		}
+		m, flashCmd := m.setFlash(text)
+		return m, tea.Batch(listCmd, flashCmd)
+
+	case msg.FavoritesChangedMsg:
+		m.store.Favorites = teaMsg.Favorites
+		st := m.store
+		return m, func() tea.Msg {
+			if err := st.Save(); err != nil {
+				return flashMsg{text: err.Error()}
+			}
+			return nil
+		}
+
 	case msg.RepoSelectedMsg:
+		m.push()
 		m.selectedRepo = teaMsg.Repo
+		m.buildQuery = buildquery.Query{}
 		m.state = stateLoadingBuilds
 		m.loadingStartTime = time.Now()
 		return m, tea.Batch(m.spinner.Tick, m.loadBuildsCmd(teaMsg.Repo.Namespace, teaMsg.Repo.Name))
@@ -176,9 +399,8 @@ func (m Model) Update(teaMsg tea.Msg) (tea.Model, tea.Cmd) {
 	case msg.BuildsLoadedMsg:
 		if teaMsg.Err != nil {
 			m.err = teaMsg.Err
-			m.state = stateRepoList
 			m.isRefreshing = false
-			return m, nil
+			return m.back()
 		}
 		elapsed := time.Since(m.loadingStartTime)
 		if elapsed < minLoadingDuration {
@@ -188,23 +410,184 @@ func (m Model) Update(teaMsg tea.Msg) (tea.Model, tea.Cmd) {
 			})
 		}
 		// Account for statusbar height
-		m.buildList = builds.New(teaMsg.Builds, m.selectedRepo.Slug, m.width, m.height-1)
+		m.buildList = m.newBuildList(teaMsg.Builds)
 		m.state = stateBuildList
 		m.isRefreshing = false
-		return m, nil
+		return m.schedulePreview()
+
+	case msg.GoToBuildMsg:
+		return m.goToBuild(teaMsg)
+
+	case buildFoundMsg:
+		return m.openFoundBuild(teaMsg)
+
+	case branchBuildMsg:
+		return m.openBranchBuild(teaMsg)
+
+	case msg.CompareBuildsMsg:
+		return m.compareBuilds(teaMsg)
+
+	case comparisonLoadedMsg:
+		return m.openComparison(teaMsg)
 
 	case msg.BuildSelectedMsg:
+		m.push()
 		m.selectedBuild = teaMsg.Build
 		m.state = stateLoadingBuild
 		m.loadingStartTime = time.Now()
 		return m, tea.Batch(m.spinner.Tick, m.loadBuildCmd(m.selectedRepo.Namespace, m.selectedRepo.Name, int(teaMsg.Build.Number)))
 
+	case msg.DashboardBuildSelectedMsg:
+		m.push()
+		m.selectedRepo = teaMsg.Repo
+		m.selectedBuild = teaMsg.Build
+		m.state = stateLoadingBuild
+		m.loadingStartTime = time.Now()
+		return m, tea.Batch(m.spinner.Tick, m.loadBuildCmd(teaMsg.Repo.Namespace, teaMsg.Repo.Name, int(teaMsg.Build.Number)))
+
+	case msg.DashboardLoadedMsg:
+		if teaMsg.Scan && teaMsg.Err == nil {
+			m.dashboardScanned = teaMsg.Scanned
+			m.dashboardScannedAt = time.Now()
+		}
+		// Scanned failures go last, behind the fresher listings of this load
+		return m, m.dashboard.SetRepos(append(teaMsg.Repos, m.dashboardScanned...), teaMsg.Err)
+
+	case autoRefreshMsg:
+		var cmds []tea.Cmd
+		switch m.state {
+		case stateRepoList:
+			cmds = append(cmds, m.refreshReposCmd())
+		case stateBuildList:
+			cmds = append(cmds, m.refreshBuildsCmd(m.selectedRepo))
+		}
+		cmds = append(cmds, m.autoRefreshTickCmd())
+		return m, tea.Batch(cmds...)
+
+	case msg.ReposRefreshedMsg:
+		if teaMsg.Err != nil || m.state != stateRepoList {
+			return m, nil
+		}
+		return m, m.repoList.Merge(teaMsg.Repos)
+
+	case msg.BuildsRefreshedMsg:
+		if teaMsg.Err != nil || m.selectedRepo == nil || teaMsg.RepoSlug != m.selectedRepo.Slug ||
+			teaMsg.Branch != m.buildQuery.ServerBranch() {
+			return m, nil
+		}
+		if teaMsg.Branch != m.buildListBranch {
+			// A different server-side branch is a new list, not an update
+			m.buildListBranch = teaMsg.Branch
+			return m, m.buildList.Replace(teaMsg.Builds)
+		}
+		return m, m.buildList.Merge(teaMsg.Builds)
+
+	case msg.BranchesLoadedMsg:
+		if m.selectedRepo == nil || teaMsg.RepoSlug != m.selectedRepo.Slug {
+			return m, nil
+		}
+		return m, m.branchList.SetBuilds(teaMsg.Builds, teaMsg.Err)
+
+	case msg.DeploymentsLoadedMsg:
+		if m.selectedRepo == nil || teaMsg.RepoSlug != m.selectedRepo.Slug {
+			return m, nil
+		}
+		return m, m.deployments.SetDeployments(teaMsg.Latest, teaMsg.History, teaMsg.Err)
+
+	case msg.CronsLoadedMsg:
+		if m.selectedRepo == nil || teaMsg.RepoSlug != m.selectedRepo.Slug {
+			return m, nil
+		}
+		return m, m.crons.SetCrons(teaMsg.Crons, teaMsg.LastBuilds, teaMsg.Err)
+
+	case msg.InsightsLoadedMsg:
+		if m.selectedRepo == nil || teaMsg.RepoSlug != m.selectedRepo.Slug {
+			return m, nil
+		}
+		return m, m.insights.SetBuilds(teaMsg.Builds, teaMsg.Cached, teaMsg.Err)
+
+	case msg.CronActionMsg:
+		return m, m.cronActionCmd(teaMsg)
+
+	case msg.CronActionDoneMsg:
+		return m.handleCronDone(teaMsg)
+
+	case msg.SecretsLoadedMsg:
+		if m.selectedRepo == nil || teaMsg.RepoSlug != m.selectedRepo.Slug || teaMsg.Org != m.secrets.Org() {
+			return m, nil
+		}
+		return m, m.secrets.SetSecrets(teaMsg.Secrets, teaMsg.Err)
+
+	case msg.SecretScopeChangedMsg:
+		return m, m.loadSecretsCmd(teaMsg.Org)
+
+	case msg.SecretActionMsg:
+		return m, m.secretActionCmd(teaMsg)
+
+	case msg.SecretActionDoneMsg:
+		return m.handleSecretDone(teaMsg)
+
+	case msg.RepoActionMsg:
+		return m, m.repoActionCmd(teaMsg)
+
+	case msg.RepoActionDoneMsg:
+		return m.handleRepoDone(teaMsg)
+
+	case msg.BranchSelectedMsg:
+		// Open the branch's build history through the server-side filter
+		m.push()
+		m.buildQuery = buildquery.Query{Branch: teaMsg.Branch}
+		m.state = stateLoadingBuilds
+		m.loadingStartTime = time.Now()
+		return m, tea.Batch(m.spinner.Tick, m.loadBuildsCmd(m.selectedRepo.Namespace, m.selectedRepo.Name))
+
+	case msg.BuildQueryChangedMsg:
+		previous := m.buildQuery.ServerBranch()
+		m.buildQuery = teaMsg.Query
+		if m.buildQuery.ServerBranch() != previous {
+			return m, m.refreshBuildsCmd(m.selectedRepo)
+		}
+		return m, nil
+
+	case watchTickMsg:
+		if len(m.watches) == 0 {
+			m.watchPolling = false
+			return m, nil
+		}
+		return m, tea.Batch(m.pollWatchesCmd(), m.watchTickCmd())
+
+	case watchResultMsg:
+		return m.handleWatchResult(teaMsg)
+
+	case flashMsg:
+		return m.setFlash(teaMsg.text)
+
+	case clearFlashMsg:
+		if teaMsg.gen == m.flashGen {
+			m.flash = ""
+			if m.state == stateRepoList {
+				m.repoList.SetSize(m.width, m.repoListHeight())
+			}
+		}
+		return m, nil
+
+	case dashboardTickMsg:
+		if teaMsg.gen != m.dashboardGen || m.state != stateDashboard {
+			return m, nil
+		}
+		scan := time.Since(m.dashboardScannedAt) >= failureScanInterval
+		return m, tea.Batch(m.loadDashboardCmd(scan), m.dashboardTickCmd())
+
 	case msg.BuildLoadedMsg:
 		if teaMsg.Err != nil {
 			m.err = teaMsg.Err
-			m.state = stateBuildList
-			m.isRefreshing = false
-			return m, nil
+			if m.isRefreshing {
+				// Keep showing the logs that were being refreshed
+				m.isRefreshing = false
+				m.state = stateLogViewer
+				return m, nil
+			}
+			return m.back()
 		}
 		elapsed := time.Since(m.loadingStartTime)
 		if elapsed < minLoadingDuration {
@@ -225,15 +608,16 @@ func (m Model) Update(teaMsg tea.Msg) (tea.Model, tea.Cmd) {
 		switch m.state {
 		case stateLoadingRepos:
 			if m.pendingRepos != nil {
-				m.repoList = repos.New(m.pendingRepos, m.width, m.height)
+				m.repoList = repos.New(m.pendingRepos, m.store.Favorites, m.width, m.repoListHeight())
 				m.pendingRepos = nil
 				m.state = stateRepoList
 			}
 		case stateLoadingBuilds:
 			if m.pendingBuilds != nil {
-				m.buildList = builds.New(m.pendingBuilds, m.selectedRepo.Slug, m.width, m.height-1)
+				m.buildList = m.newBuildList(m.pendingBuilds)
 				m.pendingBuilds = nil
 				m.state = stateBuildList
+				return m.schedulePreview()
 			}
 		case stateLoadingBuild:
 			if m.pendingBuild != nil {
@@ -255,29 +639,279 @@ func (m Model) Update(teaMsg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 
 	case stateRepoList:
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && !m.repoList.IsFiltering() {
+			if keymap.Matches(kmsg, m.prevKey, keymap.Dashboard) {
+				m.push()
+				m.dashboard = dashboard.New(m.width, m.height-1) // Account for statusbar
+				return m.enterDashboard()
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.Sync) {
+				m.state = stateLoadingRepos
+				m.isRefreshing = true
+				m.isSyncing = true
+				m.repoList.SetSize(m.width, m.height-1) // Make room for the progress bar
+				return m, tea.Batch(m.spinner.Tick, m.syncReposCmd())
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.Settings) {
+				if repo := m.repoList.SelectedRepo(); repo != nil {
+					if m.selectedRepo == nil || m.selectedRepo.Slug != repo.Slug {
+						m.buildQuery = buildquery.Query{}
+					}
+					m.push()
+					m.selectedRepo = repo
+					return m.openRepoTab(stateSettings)
+				}
+			}
+		}
 		var repoCmd tea.Cmd
 		m.repoList, repoCmd = m.repoList.Update(teaMsg)
 		return m, repoCmd
 
+	case stateDashboard:
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && !m.dashboard.IsFiltering() &&
+			keymap.Matches(kmsg, m.prevKey, keymap.Back, keymap.Dashboard) {
+			return m.back()
+		}
+		var dashboardCmd tea.Cmd
+		m.dashboard, dashboardCmd = m.dashboard.Update(teaMsg)
+		return m, dashboardCmd
+
 	case stateBuildList:
-		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && key.Matches(kmsg, keys.Back) {
-			if !m.buildList.IsFiltering() {
-				m.state = stateRepoList
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && !m.buildList.IsFiltering() {
+			if keymap.Matches(kmsg, m.prevKey, keymap.SplitView) {
+				return m.toggleSplit()
+			}
+			if m.splitActive() && m.previewFocused {
+				return m.updatePreview(kmsg)
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.FocusPane) && m.splitActive() && m.previewLoaded {
+				m.previewFocused = true
+				m.preview.SetFocused(true)
 				return m, nil
 			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.Back) {
+				return m.back()
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.BuildInfo) {
+				if build := m.buildList.SelectedBuild(); build != nil {
+					return m.openBuildInfo(build), nil
+				}
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.Watch, keymap.WatchBranch) {
+				if build := m.buildList.SelectedBuild(); build != nil {
+					return m.toggleWatch(m.newWatch(build, keymap.Matches(kmsg, m.prevKey, keymap.WatchBranch)))
+				}
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.NextTab) {
+				return m.switchRepoTab(1)
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.PrevTab) {
+				return m.switchRepoTab(-1)
+			}
+		}
+		if mouse, ok := teaMsg.(tea.MouseMsg); ok && m.splitActive() && m.previewLoaded && mouse.X > m.listWidth() {
+			var previewCmd tea.Cmd
+			m.preview, previewCmd = m.preview.Update(teaMsg)
+			return m, previewCmd
 		}
-		var buildCmd tea.Cmd
+		var buildCmd, previewCmd tea.Cmd
 		m.buildList, buildCmd = m.buildList.Update(teaMsg)
-		return m, buildCmd
+		m, previewCmd = m.schedulePreview()
+		return m, tea.Batch(buildCmd, previewCmd)
+
+	case stateBranchList:
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && !m.branchList.IsFiltering() {
+			if keymap.Matches(kmsg, m.prevKey, keymap.Back) {
+				return m.back()
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.BuildInfo) {
+				if build := m.branchList.SelectedBuild(); build != nil {
+					return m.openBuildInfo(build), nil
+				}
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.Watch, keymap.WatchBranch) {
+				if build := m.branchList.SelectedBuild(); build != nil {
+					return m.toggleWatch(m.newWatch(build, keymap.Matches(kmsg, m.prevKey, keymap.WatchBranch)))
+				}
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.NextTab) {
+				return m.switchRepoTab(1)
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.PrevTab) {
+				return m.switchRepoTab(-1)
+			}
+		}
+		var branchCmd tea.Cmd
+		m.branchList, branchCmd = m.branchList.Update(teaMsg)
+		return m, branchCmd
+
+	case stateDeployments:
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && !m.deployments.IsFiltering() {
+			// esc inside an environment's history returns to the overview
+			if keymap.Matches(kmsg, m.prevKey, keymap.Back) && !m.deployments.InHistory() {
+				return m.back()
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.BuildInfo) {
+				if build := m.deployments.SelectedBuild(); build != nil {
+					return m.openBuildInfo(build), nil
+				}
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.Watch, keymap.WatchBranch) {
+				if build := m.deployments.SelectedBuild(); build != nil {
+					return m.toggleWatch(m.newWatch(build, keymap.Matches(kmsg, m.prevKey, keymap.WatchBranch)))
+				}
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.NextTab) {
+				return m.switchRepoTab(1)
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.PrevTab) {
+				return m.switchRepoTab(-1)
+			}
+		}
+		var deploymentsCmd tea.Cmd
+		m.deployments, deploymentsCmd = m.deployments.Update(teaMsg)
+		return m, deploymentsCmd
+
+	case stateCrons:
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && !m.crons.IsFiltering() {
+			if keymap.Matches(kmsg, m.prevKey, keymap.Back) {
+				return m.back()
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.BuildInfo) {
+				if build := m.crons.SelectedBuild(); build != nil {
+					return m.openBuildInfo(build), nil
+				}
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.NextTab) {
+				return m.switchRepoTab(1)
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.PrevTab) {
+				return m.switchRepoTab(-1)
+			}
+		}
+		var cronsCmd tea.Cmd
+		m.crons, cronsCmd = m.crons.Update(teaMsg)
+		return m, cronsCmd
+
+	case stateSecrets:
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && !m.secrets.IsFiltering() {
+			if keymap.Matches(kmsg, m.prevKey, keymap.Back) {
+				return m.back()
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.NextTab) {
+				return m.switchRepoTab(1)
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.PrevTab) {
+				return m.switchRepoTab(-1)
+			}
+		}
+		var secretsCmd tea.Cmd
+		m.secrets, secretsCmd = m.secrets.Update(teaMsg)
+		return m, secretsCmd
+
+	case stateSettings:
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && !m.settings.IsFiltering() && !m.settings.Busy() {
+			if keymap.Matches(kmsg, m.prevKey, keymap.Back) {
+				return m.back()
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.NextTab) {
+				return m.switchRepoTab(1)
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.PrevTab) {
+				return m.switchRepoTab(-1)
+			}
+		}
+		var settingsCmd tea.Cmd
+		m.settings, settingsCmd = m.settings.Update(teaMsg)
+		return m, settingsCmd
+
+	case stateInsights:
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && !m.insights.IsFiltering() {
+			if keymap.Matches(kmsg, m.prevKey, keymap.Back) {
+				return m.back()
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.NextTab) {
+				return m.switchRepoTab(1)
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.PrevTab) {
+				return m.switchRepoTab(-1)
+			}
+		}
+		var insightsCmd tea.Cmd
+		m.insights, insightsCmd = m.insights.Update(teaMsg)
+		return m, insightsCmd
 
 	case stateLogViewer:
-		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && key.Matches(kmsg, keys.Back) {
-			m.state = stateBuildList
-			return m, nil
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && !m.logViewer.IsPrompting() {
+			if keymap.Matches(kmsg, m.prevKey, keymap.Back) {
+				return m.back()
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.BuildInfo) && m.selectedBuild != nil {
+				return m.openBuildInfo(m.selectedBuild), nil
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.Watch, keymap.WatchBranch) && m.selectedBuild != nil {
+				return m.toggleWatch(m.newWatch(m.selectedBuild, keymap.Matches(kmsg, m.prevKey, keymap.WatchBranch)))
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.Compare) {
+				return m.compareWithLastGreen()
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.PrevBranchBuild) {
+				return m.stepBuildOnBranch(-1)
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.NextBranchBuild) {
+				return m.stepBuildOnBranch(1)
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.Pipeline) && m.selectedBuild != nil {
+				m.push()
+				m.pipeline = pipeline.New(m.selectedBuild, m.width, m.height-1) // Account for statusbar
+				if stageNum, stepNum, ok := m.logViewer.ActiveStep(); ok {
+					m.pipeline.SelectStep(stageNum, stepNum)
+				}
+				m.state = statePipeline
+				return m, nil
+			}
+			if keymap.Matches(kmsg, m.prevKey, keymap.Timeline) && m.selectedBuild != nil {
+				m.push()
+				m.timeline = timeline.New(m.selectedBuild, m.width, m.height-1) // Account for statusbar
+				m.state = stateTimeline
+				return m, nil
+			}
 		}
 		var logCmd tea.Cmd
 		m.logViewer, logCmd = m.logViewer.Update(teaMsg)
 		return m, logCmd
+
+	case stateBuildInfo:
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && keymap.Matches(kmsg, m.prevKey, keymap.Back, keymap.BuildInfo) {
+			return m.back()
+		}
+		var infoCmd tea.Cmd
+		m.buildInfo, infoCmd = m.buildInfo.Update(teaMsg)
+		return m, infoCmd
+
+	case statePipeline:
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && keymap.Matches(kmsg, m.prevKey, keymap.Back, keymap.Pipeline) {
+			return m.back()
+		}
+		var pipelineCmd tea.Cmd
+		m.pipeline, pipelineCmd = m.pipeline.Update(teaMsg)
+		return m, pipelineCmd
+
+	case stateTimeline:
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && keymap.Matches(kmsg, m.prevKey, keymap.Back, keymap.Timeline) {
+			return m.back()
+		}
+		var timelineCmd tea.Cmd
+		m.timeline, timelineCmd = m.timeline.Update(teaMsg)
+		return m, timelineCmd
+
+	case stateCompare:
+		if kmsg, ok := teaMsg.(tea.KeyMsg); ok && keymap.Matches(kmsg, m.prevKey, keymap.Back) {
+			return m.back()
+		}
+		var compareCmd tea.Cmd
+		m.comparison, compareCmd = m.comparison.Update(teaMsg)
+		return m, compareCmd
 	}
 
 	return m, nil
@@ -288,11 +922,21 @@ func (m Model) View() string {
 		return styles.AppStyle.Render(fmt.Sprintf("Error: %v\n\nPress q to quit.", m.err))
 	}
 
+	if m.showHelp {
+		return m.helpView.View()
+	}
+	if m.showPalette {
+		return m.palette.View()
+	}
+
 	statusBar := m.renderStatusBar()
 
 	switch m.state {
 	case stateLoadingRepos:
 		// Show repo list while refreshing, or just statusbar on initial load
+		if m.isSyncing {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.repoList.View())
+		}
 		if m.isRefreshing {
 			return m.repoList.View()
 		}
@@ -302,15 +946,18 @@ func (m Model) View() string {
 		return styles.AppStyle.Render(m.spinner.View() + " Loading repositories...")
 
 	case stateRepoList:
+		if statusBar != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.repoList.View())
+		}
 		return m.repoList.View()
 
 	case stateLoadingBuilds:
 		// Show build list while refreshing, or repo list on initial navigation
 		if m.isRefreshing {
 			if statusBar != "" {
-				return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.buildList.View())
+				return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.buildListView())
 			}
-			return m.buildList.View()
+			return m.buildListView()
 		}
 		if statusBar != "" {
 			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.repoList.View())
@@ -319,9 +966,9 @@ func (m Model) View() string {
 
 	case stateBuildList:
 		if statusBar != "" {
-			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.buildList.View())
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.buildListView())
 		}
-		return m.buildList.View()
+		return m.buildListView()
 
 	case stateLoadingBuild:
 		// Show log viewer while refreshing, or build list on initial navigation
@@ -331,16 +978,96 @@ func (m Model) View() string {
 			}
 			return m.logViewer.View()
 		}
+		// The page the build was opened from
+		returnView := m.buildListView()
+		if n := len(m.history); n > 0 {
+			switch m.history[n-1].state {
+			case stateDashboard:
+				returnView = m.dashboard.View()
+			case stateDeployments:
+				returnView = m.deployments.View()
+			case stateCrons:
+				returnView = m.crons.View()
+			case stateLogViewer:
+				returnView = m.logViewer.View()
+			}
+		}
+		if statusBar != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, returnView)
+		}
+		return returnView
+
+	case stateDashboard:
+		if statusBar != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.dashboard.View())
+		}
+		return m.dashboard.View()
+
+	case stateBranchList:
+		if statusBar != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.branchList.View())
+		}
+		return m.branchList.View()
+
+	case stateDeployments:
+		if statusBar != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.deployments.View())
+		}
+		return m.deployments.View()
+
+	case stateCrons:
+		if statusBar != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.crons.View())
+		}
+		return m.crons.View()
+
+	case stateSecrets:
 		if statusBar != "" {
-			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.buildList.View())
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.secrets.View())
 		}
-		return m.buildList.View()
+		return m.secrets.View()
+
+	case stateSettings:
+		if statusBar != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.settings.View())
+		}
+		return m.settings.View()
+
+	case stateInsights:
+		if statusBar != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.insights.View())
+		}
+		return m.insights.View()
 
 	case stateLogViewer:
 		if statusBar != "" {
 			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.logViewer.View())
 		}
 		return m.logViewer.View()
+
+	case stateBuildInfo:
+		if statusBar != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.buildInfo.View())
+		}
+		return m.buildInfo.View()
+
+	case statePipeline:
+		if statusBar != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.pipeline.View())
+		}
+		return m.pipeline.View()
+
+	case stateTimeline:
+		if statusBar != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.timeline.View())
+		}
+		return m.timeline.View()
+
+	case stateCompare:
+		if statusBar != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, statusBar, m.comparison.View())
+		}
+		return m.comparison.View()
 	}
 
 	return ""
@@ -350,30 +1077,23 @@ func (m Model) renderStatusBar() string {
 	var parts []string
 	var loadingText string
 
-	statusBarStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("235")).
-		Foreground(lipgloss.Color("252")).
-		Padding(0, 1)
-
-	highlightStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("235")).
-		Foreground(lipgloss.Color("63")).
-		Bold(true).
-		Padding(0, 1)
-
-	loadingStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("235")).
-		Foreground(lipgloss.Color("244")).
-		Padding(0, 1)
+	statusBarStyle := styles.BarStyle
+	highlightStyle := styles.BarAccentStyle
+	loadingStyle := styles.BarMutedStyle
 
 	switch m.state {
 	case stateLoadingRepos:
 		loadingText = "● Refreshing..."
+		if m.isSyncing {
+			loadingText = "● Syncing repositories with the remote..."
+		}
 		parts = append(parts, loadingStyle.Render(loadingText))
 
 	case stateRepoList:
-		// No statusbar for repo list
-		return ""
+		// The repo list only shows a statusbar for flashed messages
+		if m.flash == "" {
+			return ""
+		}
 
 	case stateLoadingBuilds:
 		if m.selectedRepo != nil {
@@ -381,10 +1101,9 @@ func (m Model) renderStatusBar() string {
 		}
 		loadingText = "● Refreshing..."
 
-	case stateBuildList:
-		if m.selectedRepo != nil {
-			parts = append(parts, highlightStyle.Render(m.selectedRepo.Slug))
-		}
+	case stateBuildList, stateBranchList, stateDeployments, stateCrons, stateSecrets, stateSettings, stateInsights:
+		breadcrumb, _ := m.renderBreadcrumb()
+		parts = append(parts, breadcrumb, m.renderRepoTabs())
 
 	case stateLoadingBuild:
 		if m.selectedRepo != nil {
@@ -393,9 +1112,8 @@ func (m Model) renderStatusBar() string {
 		loadingText = "● Refreshing..."
 
 	case stateLogViewer:
-		if m.selectedRepo != nil {
-			parts = append(parts, highlightStyle.Render(m.selectedRepo.Slug))
-		}
+		breadcrumb, _ := m.renderBreadcrumb()
+		parts = append(parts, breadcrumb)
 		if m.selectedBuild != nil {
 			// Truncate commit message to 12 chars and strip newlines
 			msg := strings.ReplaceAll(m.selectedBuild.Message, "\n", " ")
@@ -407,6 +1125,35 @@ func (m Model) renderStatusBar() string {
 		}
 		// Add log tabs to statusbar
 		parts = append(parts, m.logViewer.RenderStatusBar())
+
+	case stateDashboard:
+		breadcrumb, _ := m.renderBreadcrumb()
+		parts = append(parts, breadcrumb)
+		parts = append(parts, loadingStyle.Render(fmt.Sprintf("auto-refresh %s", m.activeInterval())))
+
+	case stateBuildInfo, statePipeline:
+		breadcrumb, _ := m.renderBreadcrumb()
+		parts = append(parts, breadcrumb)
+
+	case stateTimeline:
+		breadcrumb, _ := m.renderBreadcrumb()
+		parts = append(parts, breadcrumb)
+		if m.selectedBuild != nil {
+			if summary := timeline.Summary(m.selectedBuild); summary != "" {
+				parts = append(parts, loadingStyle.Render(summary))
+			}
+		}
+
+	case stateCompare:
+		breadcrumb, _ := m.renderBreadcrumb()
+		parts = append(parts, breadcrumb, m.comparison.RenderStatusBar())
+	}
+
+	if len(m.watches) > 0 {
+		parts = append(parts, loadingStyle.Render(fmt.Sprintf("◉ %d watched", len(m.watches))))
+	}
+	if m.flash != "" && loadingText == "" {
+		loadingText = m.flash
 	}
 
 	if len(parts) == 0 && loadingText == "" {
@@ -424,7 +1171,7 @@ func (m Model) renderStatusBar() string {
 			loadingWidth = lipgloss.Width(loadingStyle.Render(loadingText))
 		}
 		fillWidth := m.width - contentWidth - loadingWidth
-		fillStyle := lipgloss.NewStyle().Background(lipgloss.Color("235"))
+		fillStyle := styles.BarStyle.UnsetPadding()
 		if fillWidth > 0 {
 			joined = joined + fillStyle.Render(strings.Repeat(" ", fillWidth))
 		}
@@ -435,18 +1182,165 @@ func (m Model) renderStatusBar() string {
 	return joined
 }
 
+// repoListHeight is the height left to the repo list, which gives up a
+// line to the statusbar only while a message is flashed
+func (m Model) repoListHeight() int {
+	if m.flash != "" {
+		return m.height - 1 // Account for statusbar
+	}
+	return m.height
+}
+
 func (m *Model) propagateSize() Model {
 	switch m.state {
 	case stateRepoList:
-		m.repoList.SetSize(m.width, m.height)
+		m.repoList.SetSize(m.width, m.repoListHeight())
 	case stateBuildList:
-		m.buildList.SetSize(m.width, m.height-1) // Account for statusbar
+		m.buildList.SetSize(m.listWidth(), m.height-1) // Account for statusbar
+		m.preview.SetSize(m.previewWidth(), m.height-1)
 	case stateLogViewer:
 		m.logViewer.SetSize(m.width, m.height-1) // Account for statusbar
+	case stateBuildInfo:
+		m.buildInfo.SetSize(m.width, m.height-1) // Account for statusbar
+	case statePipeline:
+		m.pipeline.SetSize(m.width, m.height-1) // Account for statusbar
+	case stateTimeline:
+		m.timeline.SetSize(m.width, m.height-1) // Account for statusbar
+	case stateCompare:
+		m.comparison.SetSize(m.width, m.height-1) // Account for statusbar
+	case stateDashboard:
+		m.dashboard.SetSize(m.width, m.height-1) // Account for statusbar
+	case stateBranchList:
+		m.branchList.SetSize(m.width, m.height-1) // Account for statusbar
+	case stateDeployments:
+		m.deployments.SetSize(m.width, m.height-1) // Account for statusbar
+	case stateCrons:
+		m.crons.SetSize(m.width, m.height-1) // Account for statusbar
+	case stateSecrets:
+		m.secrets.SetSize(m.width, m.height-1) // Account for statusbar
+	case stateSettings:
+		m.settings.SetSize(m.width, m.height-1) // Account for statusbar
+	case stateInsights:
+		m.insights.SetSize(m.width, m.height-1) // Account for statusbar
 	}
 	return *m
 }
 
+// isTyping reports whether the current view is capturing text input, in
+// which case global keybindings must not fire
+func (m Model) isTyping() bool {
+	switch m.state {
+	case stateRepoList:
+		return m.repoList.IsFiltering()
+	case stateBuildList:
+		return m.buildList.IsFiltering()
+	case stateDashboard:
+		return m.dashboard.IsFiltering()
+	case stateBranchList:
+		return m.branchList.IsFiltering()
+	case stateDeployments:
+		return m.deployments.IsFiltering()
+	case stateCrons:
+		return m.crons.IsFiltering()
+	case stateSecrets:
+		return m.secrets.IsFiltering()
+	case stateSettings:
+		return m.settings.IsFiltering()
+	case stateInsights:
+		return m.insights.IsFiltering()
+	case stateLogViewer:
+		return m.logViewer.IsPrompting()
+	}
+	return false
+}
+
+// updateHelp handles keys while the help overlay is open
+func (m Model) updateHelp(k tea.KeyMsg) (Model, tea.Cmd) {
+	if keymap.Matches(k, m.prevKey, keymap.Quit) {
+		return m, tea.Quit
+	}
+	if keymap.Matches(k, m.prevKey, keymap.Back, keymap.ShowHelp) {
+		m.lastKey = ""
+		m.showHelp = false
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.helpView, cmd = m.helpView.Update(k)
+	return m, cmd
+}
+
+// scope returns the keymap scope of the current state, listed first in the
+// help overlay
+func (m Model) scope() keymap.Scope {
+	switch m.state {
+	case stateRepoList:
+		return keymap.Repos
+	case stateDashboard:
+		return keymap.Dash
+	case stateBuildList:
+		if m.splitActive() && m.previewFocused {
+			return keymap.Preview
+		}
+		return keymap.Builds
+	case stateBranchList:
+		return keymap.Branches
+	case stateDeployments:
+		return keymap.Deployments
+	case stateCrons:
+		return keymap.Crons
+	case stateSecrets:
+		return keymap.Secrets
+	case stateSettings:
+		return keymap.RepoConfig
+	case stateInsights:
+		return keymap.Insights
+	case stateLogViewer:
+		return keymap.Logs
+	case stateBuildInfo:
+		return keymap.Info
+	case statePipeline:
+		return keymap.Graph
+	case stateTimeline:
+		return keymap.Times
+	case stateCompare:
+		return keymap.Diff
+	}
+	return ""
+}
+
+// newBuildList builds the build list screen, carrying over the structured
+// filter
+func (m *Model) newBuildList(buildList []*drone.Build) builds.Model {
+	l := builds.New(buildList, m.selectedRepo.Slug, m.listWidth(), m.height-1) // Account for statusbar
+	l.SetQuery(m.buildQuery)
+	// A new list gets a fresh preview
+	m.previewNumber = 0
+	m.buildListRepo = m.selectedRepo.Slug
+	m.buildListBranch = m.buildQuery.ServerBranch()
+	return l
+}
+
+// newWatch creates a watch for build in the selected repo, or for its
+// branch when branch is set
+func (m Model) newWatch(build *drone.Build, branch bool) watch {
+	w := watch{repo: m.selectedRepo, status: build.Status}
+	if branch {
+		w.branch = build.Target
+	} else {
+		w.number = build.Number
+	}
+	return w
+}
+
+// openBuildInfo shows the build info pane for build, returning to the
+// current state when it is closed
+func (m Model) openBuildInfo(build *drone.Build) Model {
+	m.push()
+	m.buildInfo = buildinfo.New(build, m.width, m.height-1) // Account for statusbar
+	m.state = stateBuildInfo
+	return m
+}
+
 func (m Model) loadReposCmd() tea.Cmd {
 	return func() tea.Msg {
 		repoList, err := m.client.ListRepos()
@@ -454,9 +1348,160 @@ func (m Model) loadReposCmd() tea.Cmd {
 	}
 }
 
+// enterDashboard switches to the dashboard and starts a new auto-refresh loop
+func (m Model) enterDashboard() (Model, tea.Cmd) {
+	m.state = stateDashboard
+	m.dashboard.SetSize(m.width, m.height-1) // Account for statusbar
+	m.dashboardGen++
+	scan := time.Since(m.dashboardScannedAt) >= failureScanInterval
+	return m, tea.Batch(m.loadDashboardCmd(scan), m.dashboardTickCmd())
+}
+
+// autoRefreshTickCmd schedules the next background refresh of the repo or
+// build list, using the faster interval while something is running
+func (m Model) autoRefreshTickCmd() tea.Cmd {
+	if m.refresh.Interval <= 0 {
+		return nil
+	}
+	interval := m.refresh.Interval
+	active := (m.state == stateRepoList && m.repoList.HasActive()) ||
+		(m.state == stateBuildList && m.buildList.HasActive())
+	if active && m.refresh.ActiveInterval > 0 {
+		interval = m.refresh.ActiveInterval
+	}
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
+		return autoRefreshMsg{}
+	})
+}
+
+// syncReposCmd syncs the repo list with the remote, then reloads it so the
+// latest builds are included
+func (m Model) syncReposCmd() tea.Cmd {
+	return func() tea.Msg {
+		if _, err := m.client.SyncRepos(); err != nil {
+			return msg.ReposSyncedMsg{Err: err}
+		}
+		repoList, err := m.client.ListRepos()
+		return msg.ReposSyncedMsg{Repos: repoList, Err: err}
+	}
+}
+
+// newRepoCount counts the repos in after that weren't in before
+func newRepoCount(before, after []*drone.Repo) int {
+	seen := make(map[string]bool, len(before))
+	for _, r := range before {
+		seen[r.Slug] = true
+	}
+	n := 0
+	for _, r := range after {
+		if !seen[r.Slug] {
+			n++
+		}
+	}
+	return n
+}
+
+func (m Model) refreshReposCmd() tea.Cmd {
+	return func() tea.Msg {
+		repoList, err := m.client.ListRepos()
+		return msg.ReposRefreshedMsg{Repos: repoList, Err: err}
+	}
+}
+
+func (m Model) refreshBuildsCmd(repo *drone.Repo) tea.Cmd {
+	if repo == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		branch := m.buildQuery.ServerBranch()
+		buildList, err := m.client.ListBuilds(repo.Namespace, repo.Name, client.ListOptions{Page: 1, Branch: branch})
+		return msg.BuildsRefreshedMsg{RepoSlug: repo.Slug, Branch: branch, Builds: buildList, Err: err}
+	}
+}
+
+func (m Model) activeInterval() time.Duration {
+	if m.refresh.ActiveInterval > 0 {
+		return m.refresh.ActiveInterval
+	}
+	return defaultActiveInterval
+}
+
+func (m Model) dashboardTickCmd() tea.Cmd {
+	gen := m.dashboardGen
+	return tea.Tick(m.activeInterval(), func(t time.Time) tea.Msg {
+		return dashboardTickMsg{gen: gen}
+	})
+}
+
+// loadDashboardCmd lists running and pending builds. With scan set it also
+// lists the recent builds of every repo with recent activity, so failures
+// behind a newer build show up too.
+func (m Model) loadDashboardCmd(scan bool) tea.Cmd {
+	return func() tea.Msg {
+		repoList, err := m.client.ListRepos()
+		if err != nil {
+			return msg.DashboardLoadedMsg{Err: err}
+		}
+
+		var entries []*drone.Repo
+		// Incomplete builds cover running builds behind a newer one and
+		// repos without recent activity, such as a build stuck for days,
+		// but the endpoint needs admin rights so errors are ignored
+		if incomplete, err := m.client.ListIncomplete(); err == nil {
+			entries = append(entries, incomplete...)
+		}
+		entries = append(entries, repoList...)
+		if !scan {
+			return msg.DashboardLoadedMsg{Repos: entries}
+		}
+		return msg.DashboardLoadedMsg{Repos: entries, Scanned: m.scanFailures(repoList), Scan: true}
+	}
+}
+
+// scanFailures lists the recent builds of every active repo and returns the
+// finished ones. Running builds aren't kept, as they'd go stale between
+// scans.
+func (m Model) scanFailures(repoList []*drone.Repo) []*drone.Repo {
+	found := make([][]*drone.Repo, len(repoList))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, dashboardWorkers)
+	for i, r := range repoList {
+		if !dashboard.Active(r) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			builds, err := m.client.ListBuilds(r.Namespace, r.Name, client.ListOptions{Page: 1})
+			if err != nil {
+				// Fall back to the latest build the repo list has
+				return
+			}
+			for _, b := range builds {
+				if !status.Finished(b.Status) {
+					continue
+				}
+				entry := *r
+				entry.Build = *b
+				found[i] = append(found[i], &entry)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var scanned []*drone.Repo
+	for _, f := range found {
+		scanned = append(scanned, f...)
+	}
+	return scanned
+}
+
 func (m Model) loadBuildsCmd(namespace, name string) tea.Cmd {
 	return func() tea.Msg {
-		buildList, err := m.client.ListBuilds(namespace, name, 1)
+		opts := client.ListOptions{Page: 1, Branch: m.buildQuery.ServerBranch()}
+		buildList, err := m.client.ListBuilds(namespace, name, opts)
 		return msg.BuildsLoadedMsg{Builds: buildList, Err: err}
 	}
 }
@@ -483,6 +1528,8 @@ func (m Model) loadAllLogsCmd(build *drone.Build) tea.Cmd {
 					int(st.Number),
 				)
 				return msg.LogsLoadedMsg{
+					RepoSlug: m.selectedRepo.Slug,
+					BuildNum: build.Number,
 					StepName: st.Name,
 					StageNum: int(s.Number),
 					StepNum:  int(st.Number),
@@ -513,7 +1560,7 @@ func (m Model) buildCurrentURL() string {
 			return fmt.Sprintf("%s/%s/%d", serverURL, m.selectedRepo.Slug, build.Number)
 		}
 		return fmt.Sprintf("%s/%s", serverURL, m.selectedRepo.Slug)
-	case stateLogViewer:
+	case stateLogViewer, stateTimeline:
 		if m.selectedRepo == nil || m.selectedBuild == nil {
 			return ""
 		}
@@ -522,6 +1569,65 @@ func (m Model) buildCurrentURL() string {
 			return fmt.Sprintf("%s/%s/%d/%d/%d", serverURL, m.selectedRepo.Slug, m.selectedBuild.Number, stageNum, stepNum)
 		}
 		return fmt.Sprintf("%s/%s/%d", serverURL, m.selectedRepo.Slug, m.selectedBuild.Number)
+	case stateBranchList:
+		if m.selectedRepo == nil {
+			return ""
+		}
+		if build := m.branchList.SelectedBuild(); build != nil {
+			return fmt.Sprintf("%s/%s/%d", serverURL, m.selectedRepo.Slug, build.Number)
+		}
+		return fmt.Sprintf("%s/%s", serverURL, m.selectedRepo.Slug)
+	case stateDeployments:
+		if m.selectedRepo == nil {
+			return ""
+		}
+		if build := m.deployments.SelectedBuild(); build != nil {
+			return fmt.Sprintf("%s/%s/%d", serverURL, m.selectedRepo.Slug, build.Number)
+		}
+		return fmt.Sprintf("%s/%s", serverURL, m.selectedRepo.Slug)
+	case stateCrons:
+		if m.selectedRepo == nil {
+			return ""
+		}
+		if build := m.crons.SelectedBuild(); build != nil {
+			return fmt.Sprintf("%s/%s/%d", serverURL, m.selectedRepo.Slug, build.Number)
+		}
+		return fmt.Sprintf("%s/%s/settings/cron", serverURL, m.selectedRepo.Slug)
+	case stateSecrets:
+		if m.selectedRepo == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s/%s/settings/secrets", serverURL, m.selectedRepo.Slug)
+	case stateSettings:
+		if m.selectedRepo == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s/%s/settings", serverURL, m.selectedRepo.Slug)
+	case stateInsights:
+		if m.selectedRepo == nil {
+			return ""
+		}
+		if build := m.insights.LastFailed(); build != nil {
+			return fmt.Sprintf("%s/%s/%d", serverURL, m.selectedRepo.Slug, build.Number)
+		}
+		return fmt.Sprintf("%s/%s", serverURL, m.selectedRepo.Slug)
+	case stateDashboard:
+		if repo, build := m.dashboard.Selected(); repo != nil {
+			return fmt.Sprintf("%s/%s/%d", serverURL, repo.Slug, build.Number)
+		}
+	case stateBuildInfo:
+		if m.selectedRepo == nil || m.buildInfo.Build() == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s/%s/%d", serverURL, m.selectedRepo.Slug, m.buildInfo.Build().Number)
+	case statePipeline:
+		if m.selectedRepo == nil || m.selectedBuild == nil {
+			return ""
+		}
+		if stageNum, stepNum, ok := m.pipeline.SelectedStep(); ok {
+			return fmt.Sprintf("%s/%s/%d/%d/%d", serverURL, m.selectedRepo.Slug, m.selectedBuild.Number, stageNum, stepNum)
+		}
+		return fmt.Sprintf("%s/%s/%d", serverURL, m.selectedRepo.Slug, m.selectedBuild.Number)
 	}
 	return ""
 }
@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arch-err/drone-tui/internal/client"
+	"github.com/arch-err/drone-tui/internal/config"
+	"github.com/arch-err/drone-tui/internal/store"
+	"github.com/arch-err/drone-tui/internal/tui/dashboard"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/repos"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drone/drone-go/drone"
+)
+
+// repoListModel returns the app showing a repo list of repoCount repos
+func repoListModel(repoCount int) Model {
+	var repoList []*drone.Repo
+	for i := 0; i < repoCount; i++ {
+		repoList = append(repoList, &drone.Repo{Namespace: "octocat", Name: string(rune('a' + i)), Slug: "octocat/" + string(rune('a'+i)), Active: true})
+	}
+	m := New(nil, config.Config{}, store.State{})
+	m.width, m.height = 100, 30
+	m.repoList = repos.New(repoList, nil, m.width, m.height)
+	m.state = stateRepoList
+	return m
+}
+
+func update(t *testing.T, m Model, teaMsg tea.Msg) (Model, tea.Cmd) {
+	t.Helper()
+	next, cmd := m.Update(teaMsg)
+	return next.(Model), cmd
+}
+
+func TestSyncFlashOnRepoList(t *testing.T) {
+	tests := []struct {
+		name   string
+		synced msg.ReposSyncedMsg
+		want   string
+	}{
+		{"failed", msg.ReposSyncedMsg{Err: errors.New("remote unreachable")}, "Sync failed: remote unreachable"},
+		{"no new repos", msg.ReposSyncedMsg{Repos: []*drone.Repo{{Slug: "octocat/a", Namespace: "octocat", Name: "a"}}}, "Synced, no new repositories"},
+		{"new repos", msg.ReposSyncedMsg{Repos: []*drone.Repo{
+			{Slug: "octocat/a", Namespace: "octocat", Name: "a"},
+			{Slug: "octocat/new", Namespace: "octocat", Name: "new"},
+		}}, "Synced, 1 new repository"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := repoListModel(1)
+			height := strings.Count(m.View(), "\n")
+			m.state = stateLoadingRepos
+			m.isSyncing = true
+			m, _ = update(t, m, tt.synced)
+			view := m.View()
+			if !strings.Contains(view, tt.want) {
+				t.Errorf("View() doesn't show %q:\n%s", tt.want, view)
+			}
+			if lines := strings.Count(view, "\n"); lines != height {
+				t.Errorf("View() grew from %d to %d lines with the flash", height, lines)
+			}
+
+			m, _ = update(t, m, clearFlashMsg{gen: m.flashGen})
+			if view := m.View(); strings.Contains(view, tt.want) {
+				t.Errorf("View() still shows %q after the flash cleared", tt.want)
+			}
+		})
+	}
+}
+
+func TestFavoritesSaveFailureOnRepoList(t *testing.T) {
+	// A state file under a regular file can never be written
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DRONE_TUI_STATE", filepath.Join(file, "state.yaml"))
+
+	m := repoListModel(1)
+	m, cmd := update(t, m, msg.FavoritesChangedMsg{Favorites: []string{"octocat/a"}})
+	if cmd == nil {
+		t.Fatal("favorites weren't saved")
+	}
+	m, _ = update(t, m, cmd())
+	if view := m.View(); !strings.Contains(view, "saving state") {
+		t.Errorf("View() doesn't show the failed save:\n%s", view)
+	}
+}
+
+// dashboardClient serves one active repo whose newest build passed after an
+// older one failed, counting the per-repo build listings
+type dashboardClient struct {
+	client.Client
+	listed int
+}
+
+func (c *dashboardClient) ListRepos() ([]*drone.Repo, error) {
+	now := time.Now().Unix()
+	return []*drone.Repo{{Slug: "octocat/a", Namespace: "octocat", Name: "a",
+		Build: drone.Build{Number: 2, Status: "success", Created: now, Finished: now}}}, nil
+}
+
+func (c *dashboardClient) ListIncomplete() ([]*drone.Repo, error) {
+	return nil, nil
+}
+
+func (c *dashboardClient) ListBuilds(owner, name string, opts client.ListOptions) ([]*drone.Build, error) {
+	c.listed++
+	now := time.Now().Unix()
+	return []*drone.Build{
+		{Number: 2, Status: "success", Finished: now},
+		{Number: 1, Status: "failure", Finished: now},
+	}, nil
+}
+
+// dashboardLoad runs the dashboard load in the batch cmd, skipping the
+// next tick
+func dashboardLoad(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatal("no dashboard load and tick batch")
+	}
+	for _, c := range batch {
+		if loaded, ok := c().(msg.DashboardLoadedMsg); ok {
+			m, _ = update(t, m, loaded)
+		}
+	}
+	return m
+}
+
+func TestDashboardScansForFailuresOnlyNowAndThen(t *testing.T) {
+	c := &dashboardClient{}
+	m := repoListModel(1)
+	m.client = c
+	m.refresh.ActiveInterval = time.Millisecond
+	m.dashboard = dashboard.New(m.width, m.height)
+	m, cmd := m.enterDashboard()
+	m = dashboardLoad(t, m, cmd)
+	if c.listed != 1 {
+		t.Fatalf("entering listed builds %d times, want 1", c.listed)
+	}
+
+	m, cmd = update(t, m, dashboardTickMsg{gen: m.dashboardGen})
+	m = dashboardLoad(t, m, cmd)
+	if c.listed != 1 {
+		t.Errorf("tick listed builds again within %v", failureScanInterval)
+	}
+	if view := m.View(); !strings.Contains(view, "octocat/a #1") {
+		t.Errorf("View() lost the scanned failure:\n%s", view)
+	}
+
+	m.dashboardScannedAt = time.Now().Add(-failureScanInterval)
+	m, cmd = update(t, m, dashboardTickMsg{gen: m.dashboardGen})
+	dashboardLoad(t, m, cmd)
+	if c.listed != 2 {
+		t.Errorf("tick after %v listed builds %d times, want 2", failureScanInterval, c.listed)
+	}
+}
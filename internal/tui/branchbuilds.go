@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/arch-err/drone-tui/internal/client"
+	"github.com/arch-err/drone-tui/internal/tui/logs"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drone/drone-go/drone"
+)
+
+// branchPages limits how many pages of a branch's builds are searched for
+// the build before or after the open one
+const branchPages = 10
+
+// branchBuildMsg is the build found next to from on its branch
+type branchBuildMsg struct {
+	repoSlug string
+	from     int64
+	build    *drone.Build
+	err      error
+}
+
+// stepBuildOnBranch looks for the build before (dir < 0) or after the one
+// in the log viewer on the same branch
+func (m Model) stepBuildOnBranch(dir int) (Model, tea.Cmd) {
+	if m.selectedRepo == nil || m.selectedBuild == nil {
+		return m, nil
+	}
+	which := "next"
+	if dir < 0 {
+		which = "previous"
+	}
+	m, flashCmd := m.setFlash(fmt.Sprintf("Looking for the %s build on %s...", which, m.selectedBuild.Target))
+	return m, tea.Batch(flashCmd, m.branchBuildCmd(dir))
+}
+
+func (m Model) branchBuildCmd(dir int) tea.Cmd {
+	repo := m.selectedRepo
+	from := m.selectedBuild
+	return func() tea.Msg {
+		found, err := m.findOnBranch(repo, from, dir)
+		if err == nil {
+			// Listed builds lack their stages, so fetch the build in full
+			found, err = m.client.GetBuild(repo.Namespace, repo.Name, int(found.Number))
+		}
+		return branchBuildMsg{repoSlug: repo.Slug, from: from.Number, build: found, err: err}
+	}
+}
+
+// findOnBranch pages through the builds of from's branch, newest first,
+// for the closest older (dir < 0) or newer build. Numbers are compared
+// rather than counted, since other branches' builds sit in between.
+func (m Model) findOnBranch(repo *drone.Repo, from *drone.Build, dir int) (*drone.Build, error) {
+	var newer *drone.Build
+	exhausted := false
+	for page := 1; page <= branchPages; page++ {
+		builds, err := m.client.ListBuilds(repo.Namespace, repo.Name, client.ListOptions{Page: page, Branch: from.Target})
+		if err != nil {
+			return nil, err
+		}
+		if len(builds) == 0 {
+			exhausted = true
+			break
+		}
+		for _, b := range builds {
+			if b.Target != from.Target {
+				continue
+			}
+			switch {
+			case b.Number > from.Number:
+				newer = b
+			case dir > 0:
+				if newer == nil {
+					return nil, fmt.Errorf("#%d is the latest build on %s", from.Number, from.Target)
+				}
+				return newer, nil
+			case b.Number < from.Number:
+				return b, nil
+			}
+		}
+	}
+	if dir > 0 && newer != nil {
+		return newer, nil
+	}
+	if dir < 0 && exhausted {
+		return nil, fmt.Errorf("#%d is the first build on %s", from.Number, from.Target)
+	}
+	if dir < 0 {
+		return nil, fmt.Errorf("no build on %s before #%d in the last %d pages", from.Target, from.Number, branchPages)
+	}
+	return nil, fmt.Errorf("#%d not found on %s in the last %d pages", from.Number, from.Target, branchPages)
+}
+
+// openBranchBuild shows the logs of the build found on the branch, on the
+// step of the same name as before
+func (m Model) openBranchBuild(found branchBuildMsg) (Model, tea.Cmd) {
+	if m.state != stateLogViewer || m.selectedRepo == nil || m.selectedRepo.Slug != found.repoSlug ||
+		m.selectedBuild == nil || m.selectedBuild.Number != found.from {
+		// The user moved on while the build was looked up
+		return m, nil
+	}
+	if found.err != nil {
+		return m.setFlash(found.err.Error())
+	}
+	stepName := m.logViewer.ActiveStepName()
+	stageNum, _, _ := m.logViewer.ActiveStep()
+
+	m.flash = ""
+	m.push()
+	m.selectedBuild = found.build
+	m.logViewer = logs.New(found.build, m.width, m.height-1) // Account for statusbar
+	m.logViewer.SelectStepNamed(stepName, stageNum)
+	return m, m.loadAllLogsCmd(found.build)
+}
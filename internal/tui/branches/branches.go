@@ -0,0 +1,136 @@
+package branches
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/timefmt"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drone/drone-go/drone"
+)
+
+type branchItem struct {
+	build *drone.Build
+}
+
+func (i branchItem) Title() string {
+	return fmt.Sprintf("%s %s", styles.StatusIcon(i.build.Status), i.build.Target)
+}
+
+func (i branchItem) FilterValue() string {
+	return i.build.Target
+}
+
+func (i branchItem) Description() string {
+	parts := []string{fmt.Sprintf("#%d", i.build.Number), i.build.Author}
+	if t := timefmt.BuildTime(i.build); t > 0 {
+		parts = append(parts, timefmt.Ago(t))
+	}
+	return strings.Join(parts, " | ")
+}
+
+type Model struct {
+	list       list.Model
+	loaded     bool
+	err        error
+	pendingKey string
+}
+
+func New(width, height int) Model {
+	l := list.New(nil, styles.NewDelegate(), width, height)
+	styles.ApplyList(&l)
+	l.SetShowTitle(false) // Title shown in external statusbar instead
+	l.KeyMap = keymap.ListKeyMap()
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.SetStatusBarItemName("branch", "branches")
+	return Model{list: l}
+}
+
+// SetBuilds fills the list with one latest build per branch, most recently
+// active first
+func (m *Model) SetBuilds(builds []*drone.Build, err error) tea.Cmd {
+	m.loaded = true
+	m.err = err
+	if err != nil {
+		return nil
+	}
+
+	sorted := make([]*drone.Build, len(builds))
+	copy(sorted, builds)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return timefmt.BuildTime(sorted[i]) > timefmt.BuildTime(sorted[j])
+	})
+
+	items := make([]list.Item, len(sorted))
+	for i, b := range sorted {
+		items[i] = branchItem{build: b}
+	}
+	cmd := m.list.SetItems(items)
+	m.list.Select(0)
+	return cmd
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if kmsg, ok := msgin.(tea.KeyMsg); ok && !m.IsFiltering() {
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(kmsg, prev, keymap.Select):
+			if item, ok := m.list.SelectedItem().(branchItem); ok {
+				return m, func() tea.Msg {
+					return msg.BranchSelectedMsg{Branch: item.build.Target}
+				}
+			}
+
+		case keymap.Matches(kmsg, prev, keymap.Top):
+			m.list.Select(0)
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Bottom):
+			m.list.Select(len(m.list.Items()) - 1)
+			return m, nil
+
+		case keymap.IsPrefix(kmsg, keymap.Branches):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = kmsg.String()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msgin)
+	return m, cmd
+}
+
+func (m Model) View() string {
+	if !m.loaded {
+		return styles.AppStyle.Render("Loading branches...")
+	}
+	if m.err != nil {
+		return styles.AppStyle.Render(fmt.Sprintf("Error loading branches: %v", m.err))
+	}
+	return m.list.View()
+}
+
+func (m Model) IsFiltering() bool {
+	return m.list.FilterState() == list.Filtering
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.list.SetSize(w, h)
+}
+
+// SelectedBuild returns the latest build of the highlighted branch
+func (m Model) SelectedBuild() *drone.Build {
+	if item, ok := m.list.SelectedItem().(branchItem); ok {
+		return item.build
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package buildinfo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/timefmt"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drone/drone-go/drone"
+)
+
+type Model struct {
+	build      *drone.Build
+	viewport   viewport.Model
+	width      int
+	height     int
+	pendingKey string
+}
+
+func New(build *drone.Build, width, height int) Model {
+	vp := viewport.New(width, height-2) // Account for separator + help line
+	m := Model{
+		build:    build,
+		viewport: vp,
+		width:    width,
+		height:   height,
+	}
+	m.viewport.SetContent(m.renderContent())
+	return m
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if kmsg, ok := msgin.(tea.KeyMsg); ok {
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(kmsg, prev, keymap.Top):
+			m.viewport.GotoTop()
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Bottom):
+			m.viewport.GotoBottom()
+			return m, nil
+
+		case keymap.IsPrefix(kmsg, keymap.Info):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = kmsg.String()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msgin)
+	return m, cmd
+}
+
+func (m Model) View() string {
+	help := styles.HelpStyle.Render(keymap.Join(
+		"↑/↓: scroll",
+		keymap.Hint("top/bottom", keymap.Top, keymap.Bottom),
+		keymap.Help(keymap.OpenInBrowser),
+		keymap.Hint("back", keymap.BuildInfo, keymap.Back),
+		keymap.Help(keymap.ShowHelp),
+	))
+	return lipgloss.JoinVertical(lipgloss.Left, m.viewport.View(), "", help)
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.viewport.Width = w
+	m.viewport.Height = h - 2 // Account for separator + help line
+	m.viewport.SetContent(m.renderContent())
+}
+
+// Build returns the build shown in the pane
+func (m Model) Build() *drone.Build {
+	return m.build
+}
+
+func (m Model) renderContent() string {
+	b := m.build
+	if b == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	row := func(label, value string) {
+		if value == "" {
+			return
+		}
+		sb.WriteString(styles.MutedStyle.Width(12).Render(label) + value + "\n")
+	}
+	section := func(title string) {
+		sb.WriteString("\n" + styles.HeaderStyle.Render(title) + "\n")
+	}
+
+	title := fmt.Sprintf("%s #%d", styles.StatusIcon(b.Status), b.Number)
+	if b.Title != "" {
+		title += " " + b.Title
+	}
+	sb.WriteString(styles.HeaderStyle.Render(title) + "\n")
+	row("Status", styles.StatusStyle(b.Status).Render(b.Status))
+	row("Error", b.Error)
+
+	section("Commit")
+	sha := b.After
+	if sha != "" {
+		row("SHA", hyperlink(b.Link, styles.HighlightStyle.Render(sha)))
+	} else {
+		row("Link", b.Link)
+	}
+	row("Before", b.Before)
+	row("After", b.After)
+	row("Ref", b.Ref)
+	if b.Message != "" {
+		sb.WriteString("\n")
+		wrap := lipgloss.NewStyle().Width(max(m.width-2, 20))
+		sb.WriteString(wrap.Render(strings.TrimRight(b.Message, "\n\r")) + "\n")
+	}
+
+	section("Trigger")
+	row("Event", b.Event)
+	row("Action", b.Action)
+	row("Trigger", b.Trigger)
+	row("Source", b.Source)
+	row("Target", b.Target)
+	row("Fork", b.Fork)
+	row("Deploy to", b.Deploy)
+	row("Cron", b.Cron)
+	if b.Parent != 0 {
+		row("Parent", fmt.Sprintf("#%d", b.Parent))
+	}
+
+	section("People")
+	author := b.AuthorName
+	if b.AuthorEmail != "" {
+		author = strings.TrimSpace(fmt.Sprintf("%s <%s>", author, b.AuthorEmail))
+	}
+	row("Author", author)
+	row("Login", b.Author)
+	row("Avatar", b.AuthorAvatar)
+	row("Sender", b.Sender)
+
+	if len(b.Params) > 0 {
+		section("Parameters")
+		keys := make([]string, 0, len(b.Params))
+		for k := range b.Params {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sb.WriteString(fmt.Sprintf("%s=%s\n", k, b.Params[k]))
+		}
+	}
+
+	section("Timing")
+	row("Created", formatTime(b.Created))
+	row("Started", formatTime(b.Started))
+	row("Finished", formatTime(b.Finished))
+	duration := timefmt.Elapsed(b.Started, b.Finished)
+	if duration != "" && b.Finished == 0 {
+		duration += " (running)"
+	}
+	row("Duration", duration)
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// hyperlink wraps text in an OSC 8 terminal hyperlink pointing at url
+func hyperlink(url, text string) string {
+	if url == "" {
+		return text
+	}
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+func formatTime(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,134 @@
+package buildquery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/drone/drone-go/drone"
+)
+
+// Query is a structured build filter such as
+// "branch:main status:failure event:push author:alice flaky test".
+// Each field accepts comma-separated alternatives; Text holds the remaining
+// free-text terms.
+type Query struct {
+	Branch string
+	Status string
+	Event  string
+	Author string
+	Text   string
+}
+
+// Parse reads a query string. Unknown keys are an error so typos don't
+// silently match everything.
+func Parse(s string) (Query, error) {
+	var q Query
+	var text []string
+	for _, term := range strings.Fields(s) {
+		k, v, ok := strings.Cut(term, ":")
+		if !ok {
+			text = append(text, term)
+			continue
+		}
+		if v == "" {
+			return Query{}, fmt.Errorf("missing value for %q", k)
+		}
+		switch strings.ToLower(k) {
+		case "branch", "b":
+			q.Branch = v
+		case "status", "s":
+			q.Status = strings.ToLower(v)
+		case "event", "e":
+			q.Event = strings.ToLower(v)
+		case "author", "a":
+			q.Author = v
+		default:
+			return Query{}, fmt.Errorf("unknown filter %q (use branch, status, event or author)", k)
+		}
+	}
+	q.Text = strings.Join(text, " ")
+	return q, nil
+}
+
+func (q Query) String() string {
+	var parts []string
+	add := func(k, v string) {
+		if v != "" {
+			parts = append(parts, k+":"+v)
+		}
+	}
+	add("branch", q.Branch)
+	add("status", q.Status)
+	add("event", q.Event)
+	add("author", q.Author)
+	if q.Text != "" {
+		parts = append(parts, q.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (q Query) IsZero() bool {
+	return q == Query{}
+}
+
+// ServerBranch returns the branch to filter on server-side, or "" when the
+// branch filter is empty or lists several alternatives
+func (q Query) ServerBranch() string {
+	if strings.Contains(q.Branch, ",") {
+		return ""
+	}
+	return q.Branch
+}
+
+// Match reports whether b satisfies every field of the query. Branches
+// match builds of refs/heads/<branch> only, as the server-side filter does,
+// so pull requests never match and a query finds the same builds whether or
+// not it was sent to the server.
+func (q Query) Match(b *drone.Build) bool {
+	if q.Branch != "" && (b.Event == "pull_request" || !anyEqual(q.Branch, b.Target)) {
+		return false
+	}
+	if q.Status != "" && !anyEqual(q.Status, b.Status) {
+		return false
+	}
+	if q.Event != "" && !anyEqual(q.Event, b.Event) {
+		return false
+	}
+	if q.Author != "" && !anyContains(q.Author, b.Author, b.AuthorName, b.AuthorEmail) {
+		return false
+	}
+	if q.Text != "" {
+		haystack := strings.ToLower(fmt.Sprintf("#%d %s %s", b.Number, b.Message, b.After))
+		for _, term := range strings.Fields(strings.ToLower(q.Text)) {
+			if !strings.Contains(haystack, term) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// anyEqual reports whether any comma-separated alternative in want equals
+// one of the values, ignoring case
+func anyEqual(want string, values ...string) bool {
+	for _, alt := range strings.Split(want, ",") {
+		for _, v := range values {
+			if v != "" && strings.EqualFold(alt, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// anyContains is like anyEqual but matches substrings
+func anyContains(want string, values ...string) bool {
+	for _, alt := range strings.Split(strings.ToLower(want), ",") {
+		for _, v := range values {
+			if v != "" && strings.Contains(strings.ToLower(v), alt) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package buildquery
+
+import (
+	"testing"
+
+	"github.com/drone/drone-go/drone"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Query
+		wantErr bool
+	}{
+		{"", Query{}, false},
+		{"branch:main status:Failure", Query{Branch: "main", Status: "failure"}, false},
+		{"b:main,dev e:push a:alice flaky test", Query{Branch: "main,dev", Event: "push", Author: "alice", Text: "flaky test"}, false},
+		{"STATUS:error", Query{Status: "error"}, false},
+		{"branch:", Query{}, true},
+		{"bramch:main", Query{}, true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	q := Query{Branch: "main", Status: "failure,error", Author: "alice", Text: "fix"}
+	got, err := Parse(q.String())
+	if err != nil || got != q {
+		t.Errorf("Parse(%q) = %+v, %v, want %+v", q.String(), got, err, q)
+	}
+}
+
+func TestServerBranch(t *testing.T) {
+	if got := (Query{Branch: "main"}).ServerBranch(); got != "main" {
+		t.Errorf("single branch: got %q", got)
+	}
+	if got := (Query{Branch: "main,dev"}).ServerBranch(); got != "" {
+		t.Errorf("alternatives: got %q, want none", got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	b := &drone.Build{
+		Number:      42,
+		Status:      "failure",
+		Event:       "pull_request",
+		Source:      "feature",
+		Target:      "main",
+		Author:      "octocat",
+		AuthorName:  "The Octocat",
+		AuthorEmail: "octocat@example.com",
+		Message:     "Fix flaky test",
+		After:       "3d21ec53a331a6f037a91c368710b99387d012c1",
+	}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"status:failure,error", true},
+		{"status:success", false},
+		{"event:pull_request", true},
+		{"event:push", false},
+		{"author:octo", true},
+		{"author:example.com", true},
+		{"author:alice", false},
+		{"flaky", true},
+		{"#42", true},
+		{"3d21ec5", true},
+		{"flaky deploy", false},
+		{"status:failure author:octocat flaky", true},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.query, err)
+		}
+		if got := q.Match(b); got != tt.want {
+			t.Errorf("%q matched = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMatchBranch(t *testing.T) {
+	push := &drone.Build{Event: "push", Target: "main"}
+	pr := &drone.Build{Event: "pull_request", Source: "feature", Target: "main"}
+	tests := []struct {
+		query string
+		build *drone.Build
+		want  bool
+	}{
+		{"branch:main", push, true},
+		{"branch:MAIN", push, true},
+		{"branch:dev,main", push, true},
+		{"branch:dev", push, false},
+		// Same as the server-side filter: pull requests match neither the
+		// branch they merge into nor their source branch
+		{"branch:main", pr, false},
+		{"branch:feature", pr, false},
+		{"event:pull_request", pr, true},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.query, err)
+		}
+		if got := q.Match(tt.build); got != tt.want {
+			t.Errorf("%q matched %s build = %v, want %v", tt.query, tt.build.Event, got, tt.want)
+		}
+	}
+}
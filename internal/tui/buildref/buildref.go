@@ -0,0 +1,157 @@
+// Package buildref reads references to a build typed into the go to build
+// prompt: a number such as "4821" or "#4821", a commit SHA prefix such as
+// "3fa2c1e", or "~N" for the build N before the current one.
+package buildref
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// minSHA is the shortest SHA prefix accepted, to keep matches unambiguous
+const minSHA = 4
+
+// Ref is a parsed reference. Exactly one of Number, SHA and Back is set.
+type Ref struct {
+	Number int64
+	SHA    string
+	Back   int
+}
+
+// Parse reads a reference. Input made only of digits is a build number.
+func Parse(s string) (Ref, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return Ref{}, fmt.Errorf("enter a build number, SHA or ~N")
+
+	case strings.HasPrefix(s, "~"):
+		if s == "~" {
+			return Ref{Back: 1}, nil
+		}
+		n, err := strconv.Atoi(s[1:])
+		if err != nil || n < 1 {
+			return Ref{}, fmt.Errorf("%q: expected ~ followed by a positive number", s)
+		}
+		return Ref{Back: n}, nil
+	}
+
+	number := strings.TrimPrefix(s, "#")
+	if n, err := strconv.ParseInt(number, 10, 64); err == nil {
+		if n < 1 {
+			return Ref{}, fmt.Errorf("%q: build numbers start at 1", s)
+		}
+		return Ref{Number: n}, nil
+	}
+
+	sha := strings.ToLower(s)
+	if strings.Trim(sha, "0123456789abcdef") != "" {
+		return Ref{}, fmt.Errorf("%q is not a build number, SHA or ~N", s)
+	}
+	if len(sha) < minSHA {
+		return Ref{}, fmt.Errorf("%q: SHA needs at least %d characters", s, minSHA)
+	}
+	return Ref{SHA: sha}, nil
+}
+
+func (r Ref) String() string {
+	switch {
+	case r.SHA != "":
+		return r.SHA
+	case r.Back > 0:
+		return fmt.Sprintf("~%d", r.Back)
+	}
+	return fmt.Sprintf("#%d", r.Number)
+}
+
+// Resolve turns a relative reference into a build number counted back from
+// the build numbered from
+func (r Ref) Resolve(from int64) (Ref, error) {
+	if r.Back == 0 {
+		return r, nil
+	}
+	if from == 0 {
+		return Ref{}, fmt.Errorf("no build to count %s from", r)
+	}
+	if from-int64(r.Back) < 1 {
+		return Ref{}, fmt.Errorf("#%d has no build %d before it", from, r.Back)
+	}
+	return Ref{Number: from - int64(r.Back)}, nil
+}
+
+// Status tells the owner of a prompt whether the user is done with it
+type Status int
+
+const (
+	Editing Status = iota
+	Submitted
+	Canceled
+)
+
+// Prompt is the one-line go to build input
+type Prompt struct {
+	input  textinput.Model
+	ref    Ref
+	err    string
+	status Status
+}
+
+func NewPrompt(width int) (Prompt, tea.Cmd) {
+	in := textinput.New()
+	in.Prompt = "go to build: "
+	in.Placeholder = "4821, 3fa2c1e or ~1"
+	in.Width = width - len(in.Prompt) - 1
+	return Prompt{input: in}, in.Focus()
+}
+
+func (p Prompt) Update(msgin tea.Msg) (Prompt, tea.Cmd) {
+	if p.status != Editing {
+		return p, nil
+	}
+	if kmsg, ok := msgin.(tea.KeyMsg); ok {
+		switch {
+		case keymap.Matches(kmsg, "", keymap.Select):
+			ref, err := Parse(p.input.Value())
+			if err != nil {
+				p.err = err.Error()
+				return p, nil
+			}
+			p.ref = ref
+			p.status = Submitted
+			p.input.Blur()
+			return p, nil
+
+		case keymap.Matches(kmsg, "", keymap.Back):
+			p.status = Canceled
+			p.input.Blur()
+			return p, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msgin)
+	p.err = ""
+	return p, cmd
+}
+
+func (p Prompt) Status() Status {
+	return p.status
+}
+
+// Ref returns the reference entered once the prompt is Submitted
+func (p Prompt) Ref() Ref {
+	return p.ref
+}
+
+func (p Prompt) View() string {
+	if p.err != "" {
+		return p.input.View() + "  " + styles.StatusFailure.Render(p.err)
+	}
+	return p.input.View()
+}
@@ -0,0 +1,101 @@
+package buildref
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Ref
+		wantErr bool
+	}{
+		{"4821", Ref{Number: 4821}, false},
+		{"#4821", Ref{Number: 4821}, false},
+		{" 12 ", Ref{Number: 12}, false},
+		{"~", Ref{Back: 1}, false},
+		{"~3", Ref{Back: 3}, false},
+		{"3fa2c1e", Ref{SHA: "3fa2c1e"}, false},
+		{"3FA2C1E", Ref{SHA: "3fa2c1e"}, false},
+		{"", Ref{}, true},
+		{"0", Ref{}, true},
+		{"#0", Ref{}, true},
+		{"~0", Ref{}, true},
+		{"~x", Ref{}, true},
+		{"abc", Ref{}, true},
+		{"main", Ref{}, true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, in := range []string{"#4821", "~2", "3fa2c1e"} {
+		ref, err := Parse(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ref.String(); got != in {
+			t.Errorf("Parse(%q).String() = %q", in, got)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		ref     Ref
+		from    int64
+		want    Ref
+		wantErr bool
+	}{
+		{Ref{Back: 1}, 10, Ref{Number: 9}, false},
+		{Ref{Back: 9}, 10, Ref{Number: 1}, false},
+		{Ref{Back: 10}, 10, Ref{}, true},
+		{Ref{Back: 1}, 0, Ref{}, true},
+		// Absolute references don't depend on the current build
+		{Ref{Number: 4}, 0, Ref{Number: 4}, false},
+		{Ref{SHA: "3fa2c1e"}, 10, Ref{SHA: "3fa2c1e"}, false},
+	}
+	for _, tt := range tests {
+		got, err := tt.ref.Resolve(tt.from)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v.Resolve(%d) error = %v, wantErr %v", tt.ref, tt.from, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v.Resolve(%d) = %+v, want %+v", tt.ref, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	p, _ := NewPrompt(80)
+	for _, r := range "~2" {
+		p, _ = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	p, _ = p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if p.Status() != Submitted || p.Ref() != (Ref{Back: 2}) {
+		t.Errorf("status %v, ref %+v after ~2 enter", p.Status(), p.Ref())
+	}
+
+	p, _ = NewPrompt(80)
+	p, _ = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("xyz")})
+	p, _ = p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if p.Status() != Editing {
+		t.Errorf("invalid input submitted with status %v", p.Status())
+	}
+	p, _ = p.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if p.Status() != Canceled {
+		t.Errorf("status %v after esc, want Canceled", p.Status())
+	}
+}
@@ -6,22 +6,42 @@ import (
 	"strings"
 	"time"
 
+	"github.com/arch-err/drone-tui/internal/tui/buildquery"
+	"github.com/arch-err/drone-tui/internal/tui/buildref"
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
 	"github.com/arch-err/drone-tui/internal/tui/msg"
 	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/timefmt"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/drone/drone-go/drone"
 )
 
+// highlightDuration is how long builds changed by an auto-refresh stay marked
+const highlightDuration = 3 * time.Second
+
+// compareMarker flags the build marked for comparison
+const compareMarker = "⇄"
+
 type buildItem struct {
-	build *drone.Build
+	build          *drone.Build
+	highlightUntil time.Time
+	marked         bool
 }
 
 func (i buildItem) Title() string {
 	msg := strings.ReplaceAll(i.build.Message, "\n", " ")
 	msg = strings.ReplaceAll(msg, "\r", " ")
-	return fmt.Sprintf("%s #%d %s", styles.StatusIcon(i.build.Status), i.build.Number, msg)
+	title := fmt.Sprintf("%s #%d %s", styles.StatusIcon(i.build.Status), i.build.Number, msg)
+	if time.Now().Before(i.highlightUntil) {
+		title = styles.ChangedMarker + " " + title
+	}
+	if i.marked {
+		title = compareMarker + " " + title
+	}
+	return title
 }
 
 func (i buildItem) FilterValue() string {
@@ -41,9 +61,12 @@ func (i buildItem) Description() string {
 		i.build.Author,
 	}
 	if i.build.Finished > 0 {
-		parts = append(parts, timeAgo(i.build.Finished))
+		parts = append(parts, timefmt.Ago(i.build.Finished))
 	} else if i.build.Started > 0 {
-		parts = append(parts, "started "+timeAgo(i.build.Started))
+		parts = append(parts, "started "+timefmt.Ago(i.build.Started))
+	}
+	if d := timefmt.Elapsed(i.build.Started, i.build.Finished); d != "" {
+		parts = append(parts, "took "+d)
 	}
 	return strings.Join(parts, " | ")
 }
@@ -63,15 +86,12 @@ func (d compactDelegate) Render(w io.Writer, m list.Model, index int, item list.
 
 	var cursor string
 	titleStyle := lipgloss.NewStyle()
-	descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	descStyle := styles.HelpStyle
 
 	if isSelected {
-		cursor = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("63")).
-			Bold(true).
-			Render("│ ")
-		titleStyle = titleStyle.Foreground(lipgloss.Color("63")).Bold(true)
-		descStyle = descStyle.Foreground(lipgloss.Color("63"))
+		cursor = styles.HeaderStyle.Render("│ ")
+		titleStyle = styles.HeaderStyle
+		descStyle = styles.AccentStyle
 	} else {
 		cursor = "  "
 	}
@@ -92,20 +112,45 @@ func (d compactDelegate) Spacing() int {
 }
 
 type Model struct {
-	list          list.Model
-	pendingGCount int
+	list       list.Model
+	builds     []*drone.Build
+	width      int
+	height     int
+	pendingKey string
+
+	// Structured filter applied on top of the fuzzy filter
+	query     buildquery.Query
+	prompt    textinput.Model
+	prompting bool
+	promptErr string
+
+	// Go to build prompt
+	jump    buildref.Prompt
+	jumping bool
+
+	// Build marked to be compared with the next one picked
+	compareMark *drone.Build
+
+	// Builds changed by the last auto-refresh, keyed by number
+	highlightUntil map[int64]time.Time
+	// Build number to reselect once an in-flight filter completes after a
+	// merge
+	restoreNumber int64
 }
 
 func New(buildList []*drone.Build, repoSlug string, width, height int) Model {
-	items := make([]list.Item, len(buildList))
-	for i, b := range buildList {
-		items[i] = buildItem{build: b}
-	}
+	m := Model{builds: buildList, width: width, height: height}
+	items := m.items()
+
+	m.prompt = textinput.New()
+	m.prompt.Prompt = "filter: "
+	m.prompt.Placeholder = "branch:main status:failure event:push author:alice"
 
 	delegate := compactDelegate{}
 	l := list.New(items, delegate, width, height)
+	styles.ApplyList(&l)
 	l.SetShowTitle(false) // Title shown in external statusbar instead
-	l.DisableQuitKeybindings()
+	l.KeyMap = keymap.ListKeyMap()
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(true)
 
@@ -114,61 +159,199 @@ func New(buildList []*drone.Build, repoSlug string, width, height int) Model {
 		l.Select(0)
 	}
 
-	return Model{list: l}
+	m.list = l
+	return m
+}
+
+func (m Model) items() []list.Item {
+	var items []list.Item
+	for _, b := range m.builds {
+		if !m.query.Match(b) {
+			continue
+		}
+		items = append(items, buildItem{
+			build:          b,
+			highlightUntil: m.highlightUntil[b.Number],
+			marked:         m.compareMark != nil && m.compareMark.Number == b.Number,
+		})
+	}
+	return items
+}
+
+// Merge swaps in freshly loaded builds without rebuilding the list, so the
+// cursor, filter text and scroll position survive. New builds and builds
+// whose status changed are highlighted briefly.
+func (m *Model) Merge(buildList []*drone.Build) tea.Cmd {
+	previous := make(map[int64]string, len(m.builds))
+	for _, b := range m.builds {
+		previous[b.Number] = b.Status
+	}
+
+	until := time.Now().Add(highlightDuration)
+	m.highlightUntil = make(map[int64]time.Time)
+	changed := false
+	for _, b := range buildList {
+		if status, ok := previous[b.Number]; !ok || status != b.Status {
+			m.highlightUntil[b.Number] = until
+			changed = true
+		}
+	}
+
+	if selected := m.SelectedBuild(); selected != nil {
+		m.restoreNumber = selected.Number
+	}
+	m.builds = buildList
+	cmds := []tea.Cmd{m.list.SetItems(m.items())}
+	m.restoreSelection()
+
+	if changed {
+		cmds = append(cmds, tea.Tick(highlightDuration, func(t time.Time) tea.Msg {
+			return msg.ClearHighlightMsg{}
+		}))
+	}
+	return tea.Batch(cmds...)
+}
+
+// Replace swaps in a different set of builds, such as after the server-side
+// branch filter changed, without highlighting them as changes
+func (m *Model) Replace(buildList []*drone.Build) tea.Cmd {
+	m.builds = buildList
+	m.highlightUntil = nil
+	m.restoreNumber = 0
+	cmd := m.list.SetItems(m.items())
+	m.list.Select(0)
+	return cmd
+}
+
+// restoreSelection moves the cursor back to restoreNumber if it is visible
+func (m *Model) restoreSelection() {
+	if m.restoreNumber == 0 {
+		return
+	}
+	for i, item := range m.list.VisibleItems() {
+		if b, ok := item.(buildItem); ok && b.build.Number == m.restoreNumber {
+			m.list.Select(i)
+			m.restoreNumber = 0
+			return
+		}
+	}
+}
+
+// HasActive reports whether any listed build is running or pending
+func (m Model) HasActive() bool {
+	for _, b := range m.builds {
+		if b.Status == "running" || b.Status == "pending" {
+			return true
+		}
+	}
+	return false
 }
 
 func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
-	if kmsg, ok := msgin.(tea.KeyMsg); ok {
-		switch kmsg.String() {
-		case "enter":
-			if !m.IsFiltering() {
-				if item, ok := m.list.SelectedItem().(buildItem); ok {
-					return m, func() tea.Msg {
-						return msg.BuildSelectedMsg{Build: item.build}
-					}
-				}
+	if m.prompting {
+		return m.updatePrompt(msgin)
+	}
+	if m.jumping {
+		return m.updateJump(msgin)
+	}
+
+	if _, ok := msgin.(tea.KeyMsg); ok {
+		// The user moved on, don't yank the cursor back after a merge
+		m.restoreNumber = 0
+	}
+
+	if kmsg, ok := msgin.(tea.KeyMsg); ok && !m.IsFiltering() {
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(kmsg, prev, keymap.FilterBuilds):
+			m.prompting = true
+			m.promptErr = ""
+			m.prompt.SetValue(m.query.String())
+			m.prompt.CursorEnd()
+			m.layout()
+			return m, m.prompt.Focus()
+
+		case keymap.Matches(kmsg, prev, keymap.GoToBuild):
+			var cmd tea.Cmd
+			m.jump, cmd = buildref.NewPrompt(m.width)
+			m.jumping = true
+			m.layout()
+			return m, cmd
+
+		case keymap.Matches(kmsg, prev, keymap.Compare):
+			return m.markForCompare()
+
+		case keymap.Matches(kmsg, prev, keymap.ClearFilter):
+			if !m.query.IsZero() {
+				return m, m.setQuery(buildquery.Query{})
 			}
 
-		case "g":
-			// Vim binding: gg to go to top
-			if !m.IsFiltering() {
-				m.pendingGCount++
-				if m.pendingGCount == 2 {
-					m.list.Select(0)
-					m.pendingGCount = 0
+		case keymap.Matches(kmsg, prev, keymap.CycleStatus):
+			// Quick toggle: cycle the status filter
+			q := m.query
+			q.Status = cycle(statusCycle, q.Status)
+			return m, m.setQuery(q)
+
+		case keymap.Matches(kmsg, prev, keymap.CycleEvent):
+			// Quick toggle: cycle the event filter
+			q := m.query
+			q.Event = cycle(eventCycle, q.Event)
+			return m, m.setQuery(q)
+
+		case keymap.Matches(kmsg, prev, keymap.Select):
+			if item, ok := m.list.SelectedItem().(buildItem); ok {
+				return m, func() tea.Msg {
+					return msg.BuildSelectedMsg{Build: item.build}
 				}
-				return m, nil
 			}
 
-		case "G":
-			// Vim binding: G to go to bottom
-			if !m.IsFiltering() {
-				m.list.Select(len(m.list.Items()) - 1)
-				m.pendingGCount = 0
-			}
+		case keymap.Matches(kmsg, prev, keymap.Top):
+			m.list.Select(0)
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Bottom):
+			m.list.Select(len(m.list.Items()) - 1)
 			return m, nil
 
-		default:
-			// Reset pending g count on any other key
-			m.pendingGCount = 0
+		case keymap.IsPrefix(kmsg, keymap.Builds):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = kmsg.String()
+			return m, nil
 		}
 	}
 
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msgin)
+	if _, ok := msgin.(list.FilterMatchesMsg); ok {
+		m.restoreSelection()
+	}
 	return m, cmd
 }
 
 func (m Model) View() string {
+	if header := m.header(); header != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, header, m.list.View())
+	}
 	return m.list.View()
 }
 
+// IsFiltering reports whether keyboard input is captured by the fuzzy
+// filter, the query prompt or the go to build prompt
 func (m Model) IsFiltering() bool {
-	return m.list.FilterState() == list.Filtering
+	return m.prompting || m.jumping || m.list.FilterState() == list.Filtering
 }
 
 func (m *Model) SetSize(w, h int) {
-	m.list.SetSize(w, h)
+	m.width = w
+	m.height = h
+	m.layout()
+}
+
+// Builds returns every loaded build, including filtered out ones
+func (m Model) Builds() []*drone.Build {
+	return m.builds
 }
 
 func (m Model) SelectedBuild() *drone.Build {
@@ -177,31 +360,3 @@ func (m Model) SelectedBuild() *drone.Build {
 	}
 	return nil
 }
-
-func timeAgo(unix int64) string {
-	t := time.Unix(unix, 0)
-	d := time.Since(t)
-
-	switch {
-	case d < time.Minute:
-		return "just now"
-	case d < time.Hour:
-		m := int(d.Minutes())
-		if m == 1 {
-			return "1 minute ago"
-		}
-		return fmt.Sprintf("%d minutes ago", m)
-	case d < 24*time.Hour:
-		h := int(d.Hours())
-		if h == 1 {
-			return "1 hour ago"
-		}
-		return fmt.Sprintf("%d hours ago", h)
-	default:
-		days := int(d.Hours() / 24)
-		if days == 1 {
-			return "1 day ago"
-		}
-		return fmt.Sprintf("%d days ago", days)
-	}
-}
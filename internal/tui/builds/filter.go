@@ -0,0 +1,168 @@
+package builds
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/buildquery"
+	"github.com/arch-err/drone-tui/internal/tui/buildref"
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Values the quick toggles step through, starting and ending at "no filter"
+var (
+	statusCycle = []string{"", "failure", "success", "running"}
+	eventCycle  = []string{"", "push", "pull_request", "tag", "promote", "cron", "custom"}
+)
+
+func cycle(values []string, current string) string {
+	for i, v := range values {
+		if v == current {
+			return values[(i+1)%len(values)]
+		}
+	}
+	return values[0]
+}
+
+// Query returns the structured filter currently applied
+func (m Model) Query() buildquery.Query {
+	return m.query
+}
+
+// SetQuery applies q without announcing it, used to carry the filter over
+// when the list is rebuilt
+func (m *Model) SetQuery(q buildquery.Query) tea.Cmd {
+	m.query = q
+	m.layout()
+	cmd := m.list.SetItems(m.items())
+	if len(m.list.VisibleItems()) > 0 {
+		m.list.Select(0)
+	}
+	return cmd
+}
+
+// setQuery applies q and tells the app, which reloads from the server when
+// the branch changed
+func (m *Model) setQuery(q buildquery.Query) tea.Cmd {
+	return tea.Batch(m.SetQuery(q), func() tea.Msg {
+		return msg.BuildQueryChangedMsg{Query: q}
+	})
+}
+
+func (m Model) updatePrompt(msgin tea.Msg) (Model, tea.Cmd) {
+	if kmsg, ok := msgin.(tea.KeyMsg); ok {
+		switch {
+		case keymap.Matches(kmsg, "", keymap.Select):
+			q, err := buildquery.Parse(m.prompt.Value())
+			if err != nil {
+				m.promptErr = err.Error()
+				return m, nil
+			}
+			m.prompting = false
+			m.prompt.Blur()
+			return m, m.setQuery(q)
+
+		case keymap.Matches(kmsg, "", keymap.Back):
+			m.prompting = false
+			m.prompt.Blur()
+			m.layout()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.prompt, cmd = m.prompt.Update(msgin)
+	m.promptErr = ""
+	return m, cmd
+}
+
+// updateJump feeds the go to build prompt, asking the app to open the
+// build once a reference is entered
+func (m Model) updateJump(msgin tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.jump, cmd = m.jump.Update(msgin)
+	switch m.jump.Status() {
+	case buildref.Submitted:
+		m.jumping = false
+		m.layout()
+		req := msg.GoToBuildMsg{Ref: m.jump.Ref()}
+		if build := m.SelectedBuild(); build != nil {
+			req.From = build.Number
+		}
+		return m, func() tea.Msg { return req }
+	case buildref.Canceled:
+		m.jumping = false
+		m.layout()
+	}
+	return m, cmd
+}
+
+// header renders a prompt or the active query above the list
+func (m Model) header() string {
+	if m.jumping {
+		return m.jump.View()
+	}
+	if m.prompting {
+		if m.promptErr != "" {
+			return m.prompt.View() + "  " + styles.StatusFailure.Render(m.promptErr)
+		}
+		return m.prompt.View()
+	}
+	var parts []string
+	if !m.query.IsZero() {
+		parts = append(parts, styles.AccentStyle.Render("filter: "+m.query.String())+
+			styles.HelpStyle.Render("  "+keymap.Join(
+				keymap.Hint("edit", keymap.FilterBuilds),
+				keymap.Hint("clear", keymap.ClearFilter),
+				keymap.Hint("cycle status/event", keymap.CycleStatus, keymap.CycleEvent),
+			)))
+	}
+	if m.compareMark != nil {
+		parts = append(parts, styles.AccentStyle.Render(fmt.Sprintf("%s compare #%d", compareMarker, m.compareMark.Number))+
+			styles.HelpStyle.Render("  "+keymap.Hint("with highlighted", keymap.Compare)))
+	}
+	return strings.Join(parts, "   ")
+}
+
+// markForCompare marks the highlighted build, or compares it with the one
+// already marked. Picking the marked build again unmarks it.
+func (m Model) markForCompare() (Model, tea.Cmd) {
+	selected := m.SelectedBuild()
+	if selected == nil {
+		return m, nil
+	}
+	mark := m.compareMark
+	if mark != nil && mark.Number != selected.Number {
+		m.compareMark = nil
+		m.layout()
+		cmd := m.list.SetItems(m.items())
+		base, head := mark, selected
+		if base.Number > head.Number {
+			base, head = head, base
+		}
+		return m, tea.Batch(cmd, func() tea.Msg {
+			return msg.CompareBuildsMsg{Base: base, Head: head}
+		})
+	}
+	if mark == nil {
+		m.compareMark = selected
+	} else {
+		m.compareMark = nil
+	}
+	m.layout()
+	return m, m.list.SetItems(m.items())
+}
+
+// layout gives the list whatever height the header leaves over
+func (m *Model) layout() {
+	h := m.height
+	if m.header() != "" {
+		h--
+	}
+	m.prompt.Width = m.width - lipgloss.Width(m.prompt.Prompt) - 1
+	m.list.SetSize(m.width, h)
+}
@@ -0,0 +1,394 @@
+// Package compare shows two builds of a repository side by side: which
+// steps changed status, and a line diff of each step's logs with
+// timestamps, durations and ids stripped.
+package compare
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drone/drone-go/drone"
+)
+
+// context is how many unchanged lines are shown around each change
+const context = 3
+
+// side is a step as it ran in one of the two builds
+type side struct {
+	stageNum int
+	stepNum  int
+	status   string
+	lines    []string
+	err      error
+	loaded   bool
+}
+
+// step pairs the runs of a step in both builds by stage and step name.
+// A nil side means the step didn't run in that build.
+type step struct {
+	name       string
+	base, head *side
+
+	// Set once both sides are loaded
+	diffed  bool
+	changed int
+	content string
+}
+
+type Model struct {
+	base, head *drone.Build
+	steps      []step
+	activeTab  int // 0 is the summary, then one per step
+	viewport   viewport.Model
+	width      int
+	height     int
+	pendingKey string
+}
+
+// New compares base, the older build, with head
+func New(base, head *drone.Build, width, height int) Model {
+	m := Model{
+		base:     base,
+		head:     head,
+		viewport: viewport.New(width, height-2), // Account for separator + help line
+		width:    width,
+		height:   height,
+	}
+
+	stages := max(len(base.Stages), len(head.Stages))
+	index := make(map[string]int)
+	add := func(build *drone.Build, isHead bool) {
+		for _, stage := range build.Stages {
+			for _, st := range stage.Steps {
+				s := &side{stageNum: int(stage.Number), stepNum: int(st.Number), status: st.Status}
+				key := stage.Name + "/" + st.Name
+				i, ok := index[key]
+				if !ok {
+					name := st.Name
+					if stages > 1 {
+						name = stage.Name + "/" + st.Name
+					}
+					i = len(m.steps)
+					index[key] = i
+					m.steps = append(m.steps, step{name: name})
+				}
+				if isHead {
+					m.steps[i].head = s
+				} else {
+					m.steps[i].base = s
+				}
+			}
+		}
+	}
+	// Steps in the order of the newer build, then those it dropped
+	add(head, true)
+	add(base, false)
+
+	m.updateViewportContent()
+	return m
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	switch msgin := msgin.(type) {
+	case tea.KeyMsg:
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(msgin, prev, keymap.NextTab):
+			m.activeTab = (m.activeTab + 1) % (len(m.steps) + 1)
+			m.updateViewportContent()
+			return m, nil
+
+		case keymap.Matches(msgin, prev, keymap.PrevTab):
+			m.activeTab = (m.activeTab + len(m.steps)) % (len(m.steps) + 1)
+			m.updateViewportContent()
+			return m, nil
+
+		case keymap.Matches(msgin, prev, keymap.Top):
+			m.viewport.GotoTop()
+			return m, nil
+
+		case keymap.Matches(msgin, prev, keymap.Bottom):
+			m.viewport.GotoBottom()
+			return m, nil
+
+		case keymap.IsPrefix(msgin, keymap.Diff):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = msgin.String()
+			return m, nil
+		}
+
+	case msg.LogsLoadedMsg:
+		m.logsLoaded(msgin)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msgin)
+	return m, cmd
+}
+
+// logsLoaded stores the logs of one side of a step, diffing the step once
+// both are in
+func (m *Model) logsLoaded(l msg.LogsLoadedMsg) {
+	for i := range m.steps {
+		s := &m.steps[i]
+		var target *side
+		switch {
+		case l.BuildNum == m.base.Number && s.base != nil && s.base.stageNum == l.StageNum && s.base.stepNum == l.StepNum:
+			target = s.base
+		case l.BuildNum == m.head.Number && s.head != nil && s.head.stageNum == l.StageNum && s.head.stepNum == l.StepNum:
+			target = s.head
+		default:
+			continue
+		}
+
+		target.err = l.Err
+		target.loaded = true
+		target.lines = target.lines[:0]
+		for _, line := range l.Lines {
+			target.lines = append(target.lines, clean(line.Message))
+		}
+		if s.base != nil && s.head != nil && s.base.loaded && s.head.loaded {
+			s.diff(m.base.Number, m.head.Number)
+		}
+		if m.activeTab == 0 || m.activeTab == i+1 {
+			m.updateViewportContent()
+		}
+		return
+	}
+}
+
+// diff renders the changed lines of s with a few lines of context, in
+// hunks headed by their position in each log
+func (s *step) diff(baseNum, headNum int64) {
+	s.diffed = true
+	if s.base.err != nil || s.head.err != nil {
+		s.content = fmt.Sprintf("Error loading logs: %v", firstErr(s.base.err, s.head.err))
+		return
+	}
+
+	a := make([]string, len(s.base.lines))
+	for i, line := range s.base.lines {
+		a[i] = normalize(line)
+	}
+	b := make([]string, len(s.head.lines))
+	for i, line := range s.head.lines {
+		b[i] = normalize(line)
+	}
+	ops, ok := diffLines(a, b)
+	if !ok {
+		s.changed = -1
+		s.content = styles.MutedStyle.Render(fmt.Sprintf("The logs differ in more than %d lines, too many to compare line by line.", maxEdits))
+		return
+	}
+
+	// Keep the changes and the context lines around them
+	keep := make([]bool, len(ops))
+	for i, o := range ops {
+		if o.kind == opEqual {
+			continue
+		}
+		s.changed++
+		for j := max(0, i-context); j <= min(len(ops)-1, i+context); j++ {
+			keep[j] = true
+		}
+	}
+	if s.changed == 0 {
+		s.content = styles.MutedStyle.Render("No differences, apart from timestamps, durations and ids.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(styles.StatusFailure.Render(fmt.Sprintf("- #%d", baseNum)) + "  " +
+		styles.StatusSuccess.Render(fmt.Sprintf("+ #%d", headNum)) + "\n")
+	for i, o := range ops {
+		if !keep[i] {
+			continue
+		}
+		if i == 0 || !keep[i-1] {
+			sb.WriteString("\n" + styles.AccentStyle.Render(hunkHeader(ops[i:])) + "\n")
+		}
+		switch o.kind {
+		case opEqual:
+			sb.WriteString(styles.MutedStyle.Render("  "+s.head.lines[o.b]) + "\n")
+		case opDelete:
+			sb.WriteString(styles.StatusFailure.Render("- "+s.base.lines[o.a]) + "\n")
+		case opInsert:
+			sb.WriteString(styles.StatusSuccess.Render("+ "+s.head.lines[o.b]) + "\n")
+		}
+	}
+	s.content = strings.TrimRight(sb.String(), "\n")
+}
+
+// hunkHeader names the first line of a hunk in both logs, counting from 1
+func hunkHeader(ops []op) string {
+	a, b := -1, -1
+	for _, o := range ops {
+		if a < 0 && o.a >= 0 {
+			a = o.a
+		}
+		if b < 0 && o.b >= 0 {
+			b = o.b
+		}
+		if a >= 0 && b >= 0 {
+			break
+		}
+	}
+	return fmt.Sprintf("@@ line %d → line %d @@", a+1, b+1)
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Model) updateViewportContent() {
+	if m.activeTab == 0 {
+		m.viewport.SetContent(m.renderSummary())
+		return
+	}
+	s := m.steps[m.activeTab-1]
+	switch {
+	case s.base == nil:
+		m.viewport.SetContent(styles.MutedStyle.Render(fmt.Sprintf("%s only ran in #%d.", s.name, m.head.Number)))
+	case s.head == nil:
+		m.viewport.SetContent(styles.MutedStyle.Render(fmt.Sprintf("%s only ran in #%d.", s.name, m.base.Number)))
+	case !s.diffed:
+		m.viewport.SetContent("Loading...")
+	default:
+		m.viewport.SetContent(s.content)
+	}
+}
+
+// renderSummary lists every step with its status in both builds
+func (m Model) renderSummary() string {
+	var sb strings.Builder
+	sb.WriteString(styles.TitleStyle.Render(fmt.Sprintf("#%d → #%d", m.base.Number, m.head.Number)) + "\n")
+	sb.WriteString(fmt.Sprintf("%s #%d %s  →  %s #%d %s\n\n",
+		styles.StatusIcon(m.base.Status), m.base.Number, buildLabel(m.base),
+		styles.StatusIcon(m.head.Status), m.head.Number, buildLabel(m.head)))
+
+	nameWidth := 0
+	for _, s := range m.steps {
+		nameWidth = max(nameWidth, lipgloss.Width(s.name))
+	}
+	nameStyle := lipgloss.NewStyle().Width(nameWidth + 2)
+
+	var changed, same []string
+	for _, s := range m.steps {
+		line := "  " + statusPair(s) + "  " + nameStyle.Render(s.name) + styles.MutedStyle.Render(s.note(m.base.Number, m.head.Number))
+		if s.base != nil && s.head != nil && s.base.status == s.head.status {
+			same = append(same, line)
+		} else {
+			changed = append(changed, line)
+		}
+	}
+	if len(changed) > 0 {
+		sb.WriteString(styles.HeaderStyle.Render("Changed status") + "\n" + strings.Join(changed, "\n") + "\n\n")
+	}
+	if len(same) > 0 {
+		sb.WriteString(styles.HeaderStyle.Render("Same status") + "\n" + strings.Join(same, "\n"))
+	}
+	return styles.AppStyle.Render(strings.TrimRight(sb.String(), "\n"))
+}
+
+func buildLabel(b *drone.Build) string {
+	title := strings.TrimSpace(strings.SplitN(b.Message, "\n", 2)[0])
+	if len(b.After) >= 8 {
+		return styles.MutedStyle.Render(b.After[:8] + " " + title)
+	}
+	return styles.MutedStyle.Render(title)
+}
+
+// statusPair shows the status icons of both sides, a blank for a side where
+// the step didn't run
+func statusPair(s step) string {
+	icon := func(sd *side) string {
+		if sd == nil {
+			return " "
+		}
+		return styles.StatusIcon(sd.status)
+	}
+	return icon(s.base) + " → " + icon(s.head)
+}
+
+// note describes how much the logs of s changed
+func (s step) note(baseNum, headNum int64) string {
+	switch {
+	case s.base == nil:
+		return fmt.Sprintf("only in #%d", headNum)
+	case s.head == nil:
+		return fmt.Sprintf("only in #%d", baseNum)
+	case !s.diffed:
+		return "loading..."
+	case s.changed < 0:
+		return fmt.Sprintf("over %d lines changed", maxEdits)
+	case s.changed == 0:
+		return "same logs"
+	case s.changed == 1:
+		return "1 line changed"
+	}
+	return fmt.Sprintf("%d lines changed", s.changed)
+}
+
+func (m Model) View() string {
+	help := styles.HelpStyle.Render(keymap.Join(
+		keymap.Hint("switch step", keymap.NextTab, keymap.PrevTab),
+		"↑/↓: scroll",
+		keymap.Hint("top/bottom", keymap.Top, keymap.Bottom),
+		keymap.Help(keymap.Back, keymap.ShowHelp),
+	))
+	return lipgloss.JoinVertical(lipgloss.Left, m.viewport.View(), "", help)
+}
+
+// RenderStatusBar renders the summary and step tabs as a single line
+// statusbar. Steps are marked = when their logs match, ≠ when they differ.
+func (m Model) RenderStatusBar() string {
+	parts := make([]string, 0, len(m.steps)+1)
+	for i := 0; i <= len(m.steps); i++ {
+		label := "Summary"
+		if i > 0 {
+			s := m.steps[i-1]
+			mark := "≠"
+			switch {
+			case s.base == nil || s.head == nil:
+				mark = "±"
+			case !s.diffed:
+				mark = "…"
+			case s.changed == 0 && s.base.status == s.head.status:
+				mark = "="
+			}
+			label = mark + " " + s.name
+		}
+		style := styles.PillStyle
+		if i == m.activeTab {
+			style = styles.SelectedPillStyle
+		}
+		parts = append(parts, style.Render(label))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+}
+
+// Builds returns the older and newer build being compared
+func (m Model) Builds() (base, head *drone.Build) {
+	return m.base, m.head
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.viewport.Width = w
+	m.viewport.Height = h - 2 // Account for separator + help line
+}
@@ -0,0 +1,157 @@
+package compare
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxEdits caps the diff search. Logs that differ by more lines than this
+// are reported as too different rather than diffed line by line.
+const maxEdits = 2000
+
+// noise are the parts of a log line that change from run to run without
+// meaning anything, replaced before lines are compared
+var noise = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`), ""},
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), "<time>"},
+	{regexp.MustCompile(`\b\d{1,2}:\d{2}:\d{2}(\.\d+)?\b`), "<time>"},
+	{regexp.MustCompile(`\b(\d+(\.\d+)?(h|ms|µs|us|ns|m|s))+\b`), "<duration>"},
+	{regexp.MustCompile(`\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b[0-9a-f]{12,64}\b`), "<hash>"},
+}
+
+// clean strips colors and progress redraws, leaving the line as the
+// terminal last showed it
+func clean(line string) string {
+	if i := strings.LastIndex(strings.TrimRight(line, "\r\n"), "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	return strings.TrimRight(noise[0].re.ReplaceAllString(line, ""), " \t\r\n")
+}
+
+// normalize replaces timestamps, durations and ids in a cleaned line
+func normalize(line string) string {
+	for _, n := range noise[1:] {
+		line = n.re.ReplaceAllString(line, n.repl)
+	}
+	return line
+}
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is one line of a diff: a and b index the line in the old and new log.
+// Deletions only have a, insertions only b.
+type op struct {
+	kind opKind
+	a, b int
+}
+
+// diffLines returns the shortest edit script from a to b, or false when
+// they differ by more than maxEdits lines
+func diffLines(a, b []string) ([]op, bool) {
+	// Common ends are cheap to match and usually most of a log
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []op
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{kind: opEqual, a: i, b: i})
+	}
+	middle, ok := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	if !ok {
+		return nil, false
+	}
+	for _, o := range middle {
+		o.a += prefix
+		o.b += prefix
+		ops = append(ops, o)
+	}
+	for i := suffix; i > 0; i-- {
+		ops = append(ops, op{kind: opEqual, a: len(a) - i, b: len(b) - i})
+	}
+	return ops, true
+}
+
+// myers is Myers' O(ND) diff. trace[d] keeps the furthest x reached on
+// diagonals -d-1..d+1 before round d, which is all backtracking needs.
+func myers(a, b []string) ([]op, bool) {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxEdits)
+	off := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[off-d-1:off+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m), true
+			}
+		}
+	}
+	return nil, false
+}
+
+func backtrack(trace [][]int, n, m int) []op {
+	var ops []op
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, op{kind: opEqual, a: x, b: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, op{kind: opInsert, a: -1, b: prevY})
+			} else {
+				ops = append(ops, op{kind: opDelete, a: prevX, b: -1})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
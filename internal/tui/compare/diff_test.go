@@ -0,0 +1,142 @@
+package compare
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"\x1b[32mok\x1b[0m  ", "ok"},
+		{"10%\r50%\r100%\r\n", "100%"},
+		{"\x1b[1;31mFAIL\x1b[0m\ttest\t", "FAIL\ttest"},
+	}
+	for _, tt := range tests {
+		if got := clean(tt.in); got != tt.want {
+			t.Errorf("clean(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"2026-02-01T10:04:05.123Z starting", "<time> starting"},
+		{"[10:04:05] starting", "[<time>] starting"},
+		{"ok  pkg/foo  1.234s", "ok  pkg/foo  <duration>"},
+		{"took 1m30s", "took <duration>"},
+		{"id 123e4567-e89b-12d3-a456-426614174000 done", "id <uuid> done"},
+		{"sha256:3d21ec53a331a6f037a91c368710b99387d012c1", "sha256:<hash>"},
+		// Short hex and plain numbers are content, not noise
+		{"exit code 137 at cafe", "exit code 137 at cafe"},
+	}
+	for _, tt := range tests {
+		if got := normalize(tt.in); got != tt.want {
+			t.Errorf("normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// apply replays ops on a, checking they reference a and b consistently,
+// and returns the lines they produce with the number of edits
+func apply(t *testing.T, a, b []string, ops []op) ([]string, int) {
+	t.Helper()
+	var out []string
+	edits, ai, bi := 0, 0, 0
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			if o.a != ai || o.b != bi || a[o.a] != b[o.b] {
+				t.Fatalf("bad equal op %+v at a=%d b=%d", o, ai, bi)
+			}
+			out = append(out, a[o.a])
+			ai++
+			bi++
+		case opDelete:
+			if o.a != ai {
+				t.Fatalf("bad delete op %+v at a=%d", o, ai)
+			}
+			ai++
+			edits++
+		case opInsert:
+			if o.b != bi {
+				t.Fatalf("bad insert op %+v at b=%d", o, bi)
+			}
+			out = append(out, b[o.b])
+			bi++
+			edits++
+		}
+	}
+	if ai != len(a) || bi != len(b) {
+		t.Fatalf("ops cover a[:%d] and b[:%d], want %d and %d", ai, bi, len(a), len(b))
+	}
+	return out, edits
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		edits int
+	}{
+		{"", "", 0},
+		{"a b c", "a b c", 0},
+		{"", "a b", 2},
+		{"a b", "", 2},
+		{"a b c", "a x c", 2},
+		{"a b c d", "a c d e", 2},
+		{"a b c a b b a", "c b a b a c", 5},
+	}
+	for _, tt := range tests {
+		a, b := strings.Fields(tt.a), strings.Fields(tt.b)
+		ops, ok := diffLines(a, b)
+		if !ok {
+			t.Fatalf("diffLines(%q, %q) gave up", tt.a, tt.b)
+		}
+		out, edits := apply(t, a, b, ops)
+		if strings.Join(out, " ") != strings.Join(b, " ") {
+			t.Errorf("diffLines(%q, %q) produces %q", tt.a, tt.b, out)
+		}
+		if edits != tt.edits {
+			t.Errorf("diffLines(%q, %q) has %d edits, want %d", tt.a, tt.b, edits, tt.edits)
+		}
+	}
+}
+
+func TestDiffLinesRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	lines := func() []string {
+		out := make([]string, r.Intn(30))
+		for i := range out {
+			out[i] = strconv.Itoa(r.Intn(5))
+		}
+		return out
+	}
+	for i := 0; i < 500; i++ {
+		a, b := lines(), lines()
+		ops, ok := diffLines(a, b)
+		if !ok {
+			t.Fatalf("diffLines(%q, %q) gave up", a, b)
+		}
+		if out, _ := apply(t, a, b, ops); strings.Join(out, "\n") != strings.Join(b, "\n") {
+			t.Fatalf("diffLines(%q, %q) produces %q", a, b, out)
+		}
+	}
+}
+
+func TestDiffLinesTooDifferent(t *testing.T) {
+	a := make([]string, maxEdits)
+	b := make([]string, maxEdits)
+	for i := range a {
+		a[i] = "a" + strconv.Itoa(i)
+		b[i] = "b" + strconv.Itoa(i)
+	}
+	if _, ok := diffLines(a, b); ok {
+		t.Error("diffLines of two unrelated logs didn't give up")
+	}
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/arch-err/drone-tui/internal/client"
+	"github.com/arch-err/drone-tui/internal/tui/compare"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drone/drone-go/drone"
+)
+
+// comparisonLoadedMsg carries both builds of a comparison with their stages
+type comparisonLoadedMsg struct {
+	repoSlug   string
+	base, head *drone.Build
+	err        error
+}
+
+// compareBuilds loads two builds in full and compares them
+func (m Model) compareBuilds(req msg.CompareBuildsMsg) (Model, tea.Cmd) {
+	if m.selectedRepo == nil {
+		return m, nil
+	}
+	m, flashCmd := m.setFlash(fmt.Sprintf("Loading #%d and #%d...", req.Base.Number, req.Head.Number))
+	repo := m.selectedRepo
+	return m, tea.Batch(flashCmd, func() tea.Msg {
+		base, err := m.client.GetBuild(repo.Namespace, repo.Name, int(req.Base.Number))
+		if err != nil {
+			return comparisonLoadedMsg{repoSlug: repo.Slug, err: err}
+		}
+		head, err := m.client.GetBuild(repo.Namespace, repo.Name, int(req.Head.Number))
+		return comparisonLoadedMsg{repoSlug: repo.Slug, base: base, head: head, err: err}
+	})
+}
+
+// compareWithLastGreen compares the build in the log viewer with the last
+// successful build before it on the same branch
+func (m Model) compareWithLastGreen() (Model, tea.Cmd) {
+	if m.selectedRepo == nil || m.selectedBuild == nil {
+		return m, nil
+	}
+	head := m.selectedBuild
+	m, flashCmd := m.setFlash(fmt.Sprintf("Looking for the last green build on %s...", head.Target))
+	repo := m.selectedRepo
+	return m, tea.Batch(flashCmd, func() tea.Msg {
+		green, err := m.findLastGreen(repo, head)
+		if err == nil {
+			// Listed builds lack their stages, so fetch the build in full
+			green, err = m.client.GetBuild(repo.Namespace, repo.Name, int(green.Number))
+		}
+		return comparisonLoadedMsg{repoSlug: repo.Slug, base: green, head: head, err: err}
+	})
+}
+
+// findLastGreen pages through the builds of from's branch for the newest
+// successful one older than from
+func (m Model) findLastGreen(repo *drone.Repo, from *drone.Build) (*drone.Build, error) {
+	for page := 1; page <= branchPages; page++ {
+		builds, err := m.client.ListBuilds(repo.Namespace, repo.Name, client.ListOptions{Page: page, Branch: from.Target})
+		if err != nil {
+			return nil, err
+		}
+		if len(builds) == 0 {
+			break
+		}
+		for _, b := range builds {
+			if b.Target == from.Target && b.Number < from.Number && b.Status == "success" {
+				return b, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no green build on %s before #%d", from.Target, from.Number)
+}
+
+// openComparison shows the loaded builds side by side and loads their logs
+func (m Model) openComparison(loaded comparisonLoadedMsg) (Model, tea.Cmd) {
+	if m.selectedRepo == nil || m.selectedRepo.Slug != loaded.repoSlug || !isPage(m.state) {
+		// The user moved on while the builds were loading
+		return m, nil
+	}
+	if loaded.err != nil {
+		return m.setFlash(loaded.err.Error())
+	}
+	m.flash = ""
+	m.push()
+	m.comparison = compare.New(loaded.base, loaded.head, m.width, m.height-1) // Account for statusbar
+	m.state = stateCompare
+	return m, tea.Batch(m.loadAllLogsCmd(loaded.base), m.loadAllLogsCmd(loaded.head))
+}
@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/arch-err/drone-tui/internal/client"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drone/drone-go/drone"
+)
+
+func (m Model) loadCronsCmd() tea.Cmd {
+	repo := m.selectedRepo
+	return func() tea.Msg {
+		cronList, err := m.client.ListCrons(repo.Namespace, repo.Name)
+		if err != nil {
+			return msg.CronsLoadedMsg{RepoSlug: repo.Slug, Err: err}
+		}
+		// Builds are listed newest first, so the first build seen for a
+		// cron is its last run
+		last := make(map[string]*drone.Build)
+		for page := 1; page <= historyPages && len(last) < len(cronList); page++ {
+			builds, err := m.client.ListBuilds(repo.Namespace, repo.Name, client.ListOptions{Page: page})
+			if err != nil || len(builds) == 0 {
+				break
+			}
+			for _, b := range builds {
+				if b.Cron != "" && last[b.Cron] == nil {
+					last[b.Cron] = b
+				}
+			}
+		}
+		return msg.CronsLoadedMsg{RepoSlug: repo.Slug, Crons: cronList, LastBuilds: last}
+	}
+}
+
+func (m Model) cronActionCmd(req msg.CronActionMsg) tea.Cmd {
+	repo := m.selectedRepo
+	if repo == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		done := msg.CronActionDoneMsg{RepoSlug: repo.Slug, Action: req.Action, Name: req.Cron.Name}
+		switch req.Action {
+		case msg.CronCreate:
+			_, done.Err = m.client.CreateCron(repo.Namespace, repo.Name, req.Cron)
+		case msg.CronUpdate:
+			_, done.Err = m.client.UpdateCron(repo.Namespace, repo.Name, req.Cron.Name, req.Patch)
+		case msg.CronDelete:
+			done.Err = m.client.DeleteCron(repo.Namespace, repo.Name, req.Cron.Name)
+		case msg.CronRun:
+			done.Build, done.Err = m.client.RunCron(repo.Namespace, repo.Name, req.Cron.Name)
+		}
+		return done
+	}
+}
+
+// handleCronDone reports the outcome of a cron change, reloads the list and
+// opens the build started by a run. If the user has moved on to another
+// repo meanwhile, the outcome is only flashed.
+func (m Model) handleCronDone(done msg.CronActionDoneMsg) (Model, tea.Cmd) {
+	if m.selectedRepo == nil || m.selectedRepo.Slug != done.RepoSlug {
+		if done.Err != nil {
+			return m.setFlash(fmt.Sprintf("%s: cron %s: %v", done.RepoSlug, done.Name, done.Err))
+		}
+		return m.setFlash(fmt.Sprintf("%s: %s", done.RepoSlug, cronDoneText(done)))
+	}
+	if m.crons.ActionDone(done) {
+		return m, nil
+	}
+	if done.Err != nil {
+		return m.setFlash(fmt.Sprintf("Cron %s: %v", done.Name, done.Err))
+	}
+
+	m, flashCmd := m.setFlash(cronDoneText(done))
+	cmds := []tea.Cmd{flashCmd, m.loadCronsCmd()}
+	if done.Build != nil && m.state == stateCrons {
+		build := done.Build
+		cmds = append(cmds, func() tea.Msg {
+			return msg.BuildSelectedMsg{Build: build}
+		})
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// cronDoneText describes a cron change that went through
+func cronDoneText(done msg.CronActionDoneMsg) string {
+	switch done.Action {
+	case msg.CronCreate:
+		return "Created cron " + done.Name
+	case msg.CronUpdate:
+		return "Updated cron " + done.Name
+	case msg.CronDelete:
+		return "Deleted cron " + done.Name
+	case msg.CronRun:
+		return fmt.Sprintf("Started cron %s as #%d", done.Name, done.Build.Number)
+	}
+	return ""
+}
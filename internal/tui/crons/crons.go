@@ -0,0 +1,344 @@
+package crons
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arch-err/drone-tui/internal/tui/form"
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/timefmt"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drone/drone-go/drone"
+)
+
+type cronItem struct {
+	cron *drone.Cron
+	last *drone.Build
+}
+
+func (i cronItem) Title() string {
+	icon := styles.StatusPending.Render("○")
+	if i.last != nil {
+		icon = styles.StatusIcon(i.last.Status)
+	}
+	title := fmt.Sprintf("%s %s  %s", icon, i.cron.Name, styles.HelpStyle.Render(i.cron.Expr))
+	if i.cron.Disabled {
+		title += styles.StatusKilled.Render("  disabled")
+	}
+	return title
+}
+
+func (i cronItem) FilterValue() string {
+	return i.cron.Name + " " + i.cron.Branch
+}
+
+func (i cronItem) Description() string {
+	parts := []string{"branch " + i.cron.Branch}
+	if i.cron.Target != "" {
+		parts = append(parts, "target "+i.cron.Target)
+	}
+	if !i.cron.Disabled && i.cron.Next > 0 {
+		parts = append(parts, "next "+timeUntil(i.cron.Next))
+	}
+	if i.last != nil {
+		parts = append(parts, fmt.Sprintf("last #%d %s", i.last.Number, timefmt.Ago(timefmt.BuildTime(i.last))))
+	} else {
+		parts = append(parts, "never run")
+	}
+	return strings.Join(parts, " | ")
+}
+
+type Model struct {
+	list       list.Model
+	loaded     bool
+	err        error
+	width      int
+	pendingKey string
+
+	// Create/edit form; editName is the cron being edited, empty when
+	// creating a new one
+	form     form.Model
+	editing  bool
+	editName string
+
+	// Cron awaiting delete confirmation
+	confirmDelete string
+}
+
+func New(width, height int) Model {
+	l := list.New(nil, styles.NewDelegate(), width, height)
+	styles.ApplyList(&l)
+	l.SetShowTitle(false) // Title shown in external statusbar instead
+	l.KeyMap = keymap.ListKeyMap()
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.SetStatusBarItemName("cron job", "cron jobs")
+	m := Model{list: l, width: width}
+	m.SetSize(width, height)
+	return m
+}
+
+// SetCrons fills the list, keeping the cursor on the same cron where
+// possible. lastBuilds maps cron names to their newest build.
+func (m *Model) SetCrons(cronList []*drone.Cron, lastBuilds map[string]*drone.Build, err error) tea.Cmd {
+	m.loaded = true
+	m.err = err
+	if err != nil {
+		return nil
+	}
+
+	var selected string
+	if c := m.SelectedCron(); c != nil {
+		selected = c.Name
+	}
+
+	sorted := make([]*drone.Cron, len(cronList))
+	copy(sorted, cronList)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	items := make([]list.Item, len(sorted))
+	cursor := 0
+	for i, c := range sorted {
+		items[i] = cronItem{cron: c, last: lastBuilds[c.Name]}
+		if c.Name == selected {
+			cursor = i
+		}
+	}
+	cmd := m.list.SetItems(items)
+	m.list.Select(cursor)
+	return cmd
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if m.editing {
+		return m.updateForm(msgin)
+	}
+
+	kmsg, ok := msgin.(tea.KeyMsg)
+	if ok && m.confirmDelete != "" {
+		name := m.confirmDelete
+		m.confirmDelete = ""
+		if keymap.Matches(kmsg, "", keymap.Confirm) {
+			return m, action(msg.CronDelete, &drone.Cron{Name: name}, nil)
+		}
+		return m, nil
+	}
+
+	if ok && !m.IsFiltering() {
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(kmsg, prev, keymap.Select):
+			if item, ok := m.list.SelectedItem().(cronItem); ok && item.last != nil {
+				return m, func() tea.Msg {
+					return msg.BuildSelectedMsg{Build: item.last}
+				}
+			}
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.RunCron):
+			if c := m.SelectedCron(); c != nil {
+				return m, action(msg.CronRun, c, nil)
+			}
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.New):
+			m.editing = true
+			m.editName = ""
+			m.form = form.New("New cron job",
+				form.Text("name", "Name", "", "nightly"),
+				form.Text("expr", "Expression", "", "@daily or 0 0 2 * * *"),
+				form.Text("branch", "Branch", "", "main"),
+				form.Text("target", "Target", "", "optional deploy target"),
+			)
+			m.form.SetWidth(m.width)
+			return m, m.form.Init()
+
+		case keymap.Matches(kmsg, prev, keymap.Edit):
+			if c := m.SelectedCron(); c != nil {
+				m.editing = true
+				m.editName = c.Name
+				m.form = form.New("Edit cron job "+c.Name,
+					form.Text("name", "Name", c.Name, "").ReadOnly(),
+					form.Text("expr", "Expression", c.Expr, "").ReadOnly(),
+					form.Text("branch", "Branch", c.Branch, "main"),
+					form.Text("target", "Target", c.Target, "optional deploy target"),
+					form.Toggle("disabled", "Disabled", c.Disabled),
+				)
+				m.form.SetWidth(m.width)
+				return m, m.form.Init()
+			}
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.ToggleCron):
+			if c := m.SelectedCron(); c != nil {
+				disabled := !c.Disabled
+				return m, action(msg.CronUpdate, c, &drone.CronPatch{Disabled: &disabled})
+			}
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Delete):
+			if c := m.SelectedCron(); c != nil {
+				m.confirmDelete = c.Name
+			}
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Top):
+			m.list.Select(0)
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Bottom):
+			m.list.Select(len(m.list.Items()) - 1)
+			return m, nil
+
+		case keymap.IsPrefix(kmsg, keymap.Crons):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = kmsg.String()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msgin)
+	return m, cmd
+}
+
+func (m Model) updateForm(msgin tea.Msg) (Model, tea.Cmd) {
+	if m.form.Status() == form.Submitted {
+		// Waiting for the save to finish
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.form, cmd = m.form.Update(msgin)
+
+	switch m.form.Status() {
+	case form.Canceled:
+		m.editing = false
+		return m, nil
+
+	case form.Submitted:
+		branch := m.form.Value("branch")
+		target := m.form.Value("target")
+		if m.editName != "" {
+			disabled := m.form.Checked("disabled")
+			patch := &drone.CronPatch{Branch: &branch, Target: &target, Disabled: &disabled}
+			return m, action(msg.CronUpdate, &drone.Cron{Name: m.editName}, patch)
+		}
+
+		c := &drone.Cron{
+			Name:   m.form.Value("name"),
+			Expr:   m.form.Value("expr"),
+			Branch: branch,
+			Target: target,
+		}
+		switch {
+		case c.Name == "":
+			m.form.Reopen("name is required")
+		case c.Expr == "":
+			m.form.Reopen("expression is required")
+		case c.Branch == "":
+			m.form.Reopen("branch is required")
+		default:
+			return m, action(msg.CronCreate, c, nil)
+		}
+		return m, nil
+	}
+	return m, cmd
+}
+
+// ActionDone closes the form after a successful save, or reopens it with
+// the error. It reports whether the error was shown in the form.
+func (m *Model) ActionDone(done msg.CronActionDoneMsg) bool {
+	if !m.editing || done.Action == msg.CronRun || done.Action == msg.CronDelete {
+		return false
+	}
+	if done.Err != nil {
+		m.form.Reopen(done.Err.Error())
+		return true
+	}
+	m.editing = false
+	return false
+}
+
+func action(a msg.CronAction, c *drone.Cron, patch *drone.CronPatch) tea.Cmd {
+	return func() tea.Msg {
+		return msg.CronActionMsg{Action: a, Cron: c, Patch: patch}
+	}
+}
+
+func (m Model) View() string {
+	if m.editing {
+		return m.form.View()
+	}
+	if !m.loaded {
+		return styles.AppStyle.Render("Loading cron jobs...")
+	}
+	if m.err != nil {
+		return styles.AppStyle.Render(fmt.Sprintf("Error loading cron jobs: %v", m.err))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, m.header(), m.list.View())
+}
+
+func (m Model) header() string {
+	if m.confirmDelete != "" {
+		return styles.StatusFailure.Render(fmt.Sprintf("Delete cron job %s? (%s/N)", m.confirmDelete, keymap.Label(keymap.Confirm)))
+	}
+	if len(m.list.Items()) == 0 {
+		return styles.HeaderStyle.Render("No cron jobs") + styles.HelpStyle.Render("  "+keymap.Help(keymap.New))
+	}
+	return styles.HeaderStyle.Render("Cron jobs") +
+		styles.HelpStyle.Render("  "+keymap.Join(
+			keymap.Hint("last build", keymap.Select),
+			keymap.Help(keymap.RunCron, keymap.New, keymap.Edit, keymap.ToggleCron, keymap.Delete),
+		))
+}
+
+// IsFiltering reports whether keys are going to the filter, the form or
+// the delete confirmation
+func (m Model) IsFiltering() bool {
+	return m.editing || m.confirmDelete != "" || m.list.FilterState() == list.Filtering
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.list.SetSize(w, h-1) // Account for header
+	m.form.SetWidth(w)
+}
+
+// SelectedCron returns the highlighted cron job
+func (m Model) SelectedCron() *drone.Cron {
+	if item, ok := m.list.SelectedItem().(cronItem); ok {
+		return item.cron
+	}
+	return nil
+}
+
+// SelectedBuild returns the newest build of the highlighted cron job
+func (m Model) SelectedBuild() *drone.Build {
+	if item, ok := m.list.SelectedItem().(cronItem); ok {
+		return item.last
+	}
+	return nil
+}
+
+func timeUntil(unix int64) string {
+	d := time.Until(time.Unix(unix, 0))
+
+	switch {
+	case d < time.Minute:
+		return "due now"
+	case d < time.Hour:
+		return fmt.Sprintf("in %dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("in %dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("in %dd", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,250 @@
+package dashboard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/timefmt"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drone/drone-go/drone"
+)
+
+// recentFailureWindow limits which failed builds are shown
+const recentFailureWindow = 24 * time.Hour
+
+type entryItem struct {
+	repo  *drone.Repo
+	build drone.Build
+}
+
+func (i entryItem) key() string {
+	return fmt.Sprintf("%s#%d", i.repo.Slug, i.build.Number)
+}
+
+func (i entryItem) Title() string {
+	return fmt.Sprintf("%s %s #%d", styles.StatusIcon(i.build.Status), i.repo.Slug, i.build.Number)
+}
+
+func (i entryItem) FilterValue() string {
+	return fmt.Sprintf("%s #%d %s %s %s", i.repo.Slug, i.build.Number, i.build.Status, i.build.Target, i.build.Author)
+}
+
+func (i entryItem) Description() string {
+	b := i.build
+	parts := []string{b.Target, b.Author}
+	switch b.Status {
+	case "running":
+		parts = append(parts, "running "+timefmt.Elapsed(b.Started, 0))
+	case "pending":
+		parts = append(parts, "queued "+timefmt.Elapsed(b.Created, 0))
+	default:
+		if d := timefmt.Elapsed(b.Started, b.Finished); d != "" {
+			parts = append(parts, "took "+d)
+		}
+		if b.Finished > 0 {
+			parts = append(parts, timefmt.Ago(b.Finished))
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+type Model struct {
+	list       list.Model
+	loaded     bool
+	err        error
+	pendingKey string
+}
+
+func New(width, height int) Model {
+	l := list.New(nil, styles.NewDelegate(), width, height)
+	styles.ApplyList(&l)
+	l.Title = "Dashboard"
+	l.KeyMap = keymap.ListKeyMap()
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.SetStatusBarItemName("build", "builds")
+	return Model{list: l}
+}
+
+// SetRepos replaces the dashboard entries, keeping the cursor on the same
+// build where possible. Errors of background refreshes keep the entries
+// shown; only a failed first load is reported.
+func (m *Model) SetRepos(repos []*drone.Repo, err error) tea.Cmd {
+	if err != nil {
+		if !m.loaded || m.err != nil {
+			m.loaded = true
+			m.err = err
+		}
+		return nil
+	}
+	m.err = nil
+
+	var selected string
+	if item, ok := m.list.SelectedItem().(entryItem); ok {
+		selected = item.key()
+	}
+
+	entries := entries(repos)
+	items := make([]list.Item, len(entries))
+	index := 0
+	for i, e := range entries {
+		items[i] = e
+		if e.key() == selected {
+			index = i
+		}
+	}
+
+	m.loaded = true
+	cmd := m.list.SetItems(items)
+	m.list.Select(index)
+	return cmd
+}
+
+// Active reports whether r has built recently enough to have running,
+// pending or recently failed builds worth listing
+func Active(r *drone.Repo) bool {
+	b := r.Build
+	if b.Number == 0 {
+		return false
+	}
+	switch b.Status {
+	case "running", "pending":
+		return true
+	}
+	return max(b.Created, b.Finished) >= time.Now().Add(-recentFailureWindow).Unix()
+}
+
+// entries keeps running and pending builds plus recent failures, ordered
+// running first, then pending, then failures by most recent. The first
+// entry for a build wins, so callers list fresher sources first.
+func entries(repos []*drone.Repo) []entryItem {
+	seen := make(map[string]bool)
+	cutoff := time.Now().Add(-recentFailureWindow).Unix()
+
+	var out []entryItem
+	for _, r := range repos {
+		b := r.Build
+		if b.Number == 0 {
+			continue
+		}
+		switch b.Status {
+		case "running", "pending":
+		case "failure", "error", "killed":
+			if b.Finished < cutoff {
+				continue
+			}
+		default:
+			continue
+		}
+		e := entryItem{repo: r, build: b}
+		if seen[e.key()] {
+			continue
+		}
+		seen[e.key()] = true
+		out = append(out, e)
+	}
+
+	rank := func(status string) int {
+		switch status {
+		case "running":
+			return 0
+		case "pending":
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ri, rj := rank(out[i].build.Status), rank(out[j].build.Status)
+		if ri != rj {
+			return ri < rj
+		}
+		if ri == 2 {
+			return out[i].build.Finished > out[j].build.Finished
+		}
+		return out[i].build.Created < out[j].build.Created
+	})
+	return out
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if kmsg, ok := msgin.(tea.KeyMsg); ok && !m.IsFiltering() {
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(kmsg, prev, keymap.Select):
+			if item, ok := m.list.SelectedItem().(entryItem); ok {
+				build := item.build
+				return m, func() tea.Msg {
+					return msg.DashboardBuildSelectedMsg{Repo: item.repo, Build: &build}
+				}
+			}
+
+		case keymap.Matches(kmsg, prev, keymap.Top):
+			m.list.Select(0)
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Bottom):
+			m.list.Select(len(m.list.Items()) - 1)
+			return m, nil
+
+		case keymap.IsPrefix(kmsg, keymap.Dash):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = kmsg.String()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msgin)
+	return m, cmd
+}
+
+func (m Model) View() string {
+	if !m.loaded {
+		return styles.AppStyle.Render("Loading running builds...")
+	}
+	if m.err != nil {
+		return styles.AppStyle.Render(fmt.Sprintf("Error loading builds: %v", m.err)) + "\n" +
+			styles.HelpStyle.Render(keymap.Help(keymap.Refresh, keymap.Back))
+	}
+	if len(m.list.Items()) == 0 {
+		return styles.AppStyle.Render("Nothing running and no recent failures.") + "\n" +
+			styles.HelpStyle.Render(keymap.Help(keymap.Refresh, keymap.Back))
+	}
+	help := ""
+	if !m.IsFiltering() {
+		help = styles.HelpStyle.Render(keymap.Join(
+			keymap.Hint("open logs", keymap.Select),
+			keymap.Help(keymap.Refresh, keymap.OpenInBrowser, keymap.Back, keymap.ShowHelp),
+		))
+	}
+	if help != "" {
+		return m.list.View() + "\n" + help
+	}
+	return m.list.View()
+}
+
+func (m Model) IsFiltering() bool {
+	return m.list.FilterState() == list.Filtering
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.list.SetSize(w, h)
+}
+
+// Selected returns the repo and build under the cursor
+func (m Model) Selected() (*drone.Repo, *drone.Build) {
+	if item, ok := m.list.SelectedItem().(entryItem); ok {
+		build := item.build
+		return item.repo, &build
+	}
+	return nil, nil
+}
@@ -0,0 +1,248 @@
+package deployments
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/timefmt"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drone/drone-go/drone"
+)
+
+// envItem is an environment with its current and previous deployment
+type envItem struct {
+	env      string
+	current  *drone.Build
+	previous *drone.Build
+}
+
+func (i envItem) Title() string {
+	return fmt.Sprintf("%s %s  #%d", styles.StatusIcon(i.current.Status), i.env, i.current.Number)
+}
+
+func (i envItem) FilterValue() string { return i.env }
+
+func (i envItem) Description() string {
+	parts := []string{deployedBy(i.current)}
+	if i.previous != nil {
+		parts = append(parts, fmt.Sprintf("previous %s #%d", styles.StatusIcon(i.previous.Status), i.previous.Number))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// historyItem is a single deployment to the selected environment
+type historyItem struct {
+	build *drone.Build
+}
+
+func (i historyItem) Title() string {
+	msg := strings.ReplaceAll(i.build.Message, "\n", " ")
+	msg = strings.ReplaceAll(msg, "\r", " ")
+	return fmt.Sprintf("%s #%d %s", styles.StatusIcon(i.build.Status), i.build.Number, msg)
+}
+
+func (i historyItem) FilterValue() string {
+	return fmt.Sprintf("#%d %s %s %s", i.build.Number, i.build.Status, i.build.Sender, i.build.Message)
+}
+
+func (i historyItem) Description() string {
+	parts := []string{i.build.Event, deployedBy(i.build)}
+	if i.build.Parent != 0 {
+		parts = append(parts, fmt.Sprintf("from #%d", i.build.Parent))
+	}
+	return strings.Join(parts, " | ")
+}
+
+type Model struct {
+	list       list.Model
+	loaded     bool
+	err        error
+	envs       []envItem
+	history    []*drone.Build
+	env        string // environment whose history is shown, empty for the overview
+	envCursor  int
+	pendingKey string
+}
+
+func New(width, height int) Model {
+	l := list.New(nil, styles.NewDelegate(), width, height)
+	styles.ApplyList(&l)
+	l.SetShowTitle(false) // Title shown in external statusbar instead
+	l.KeyMap = keymap.ListKeyMap()
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.SetStatusBarItemName("environment", "environments")
+	return Model{list: l}
+}
+
+// SetDeployments groups deployment builds by environment. latest holds the
+// newest deployment per environment; history holds older ones where known.
+func (m *Model) SetDeployments(latest, history []*drone.Build, err error) tea.Cmd {
+	m.loaded = true
+	m.err = err
+	if err != nil {
+		return nil
+	}
+
+	byEnv := make(map[string][]*drone.Build)
+	seen := make(map[int64]bool)
+	for _, b := range append(append([]*drone.Build{}, latest...), history...) {
+		if b.Deploy == "" || seen[b.Number] {
+			continue
+		}
+		seen[b.Number] = true
+		byEnv[b.Deploy] = append(byEnv[b.Deploy], b)
+	}
+
+	m.envs = nil
+	m.history = nil
+	for env, builds := range byEnv {
+		sort.Slice(builds, func(i, j int) bool { return builds[i].Number > builds[j].Number })
+		item := envItem{env: env, current: builds[0]}
+		if len(builds) > 1 {
+			item.previous = builds[1]
+		}
+		m.envs = append(m.envs, item)
+		m.history = append(m.history, builds...)
+	}
+	sort.Slice(m.envs, func(i, j int) bool {
+		return m.envs[i].current.Created > m.envs[j].current.Created
+	})
+
+	return m.showEnvs()
+}
+
+func (m *Model) showEnvs() tea.Cmd {
+	m.env = ""
+	m.list.ResetFilter()
+	m.list.SetStatusBarItemName("environment", "environments")
+	items := make([]list.Item, len(m.envs))
+	for i, e := range m.envs {
+		items[i] = e
+	}
+	cmd := m.list.SetItems(items)
+	m.list.Select(m.envCursor)
+	return cmd
+}
+
+func (m *Model) showHistory(env string) tea.Cmd {
+	m.env = env
+	m.envCursor = m.list.Index()
+	m.list.ResetFilter()
+	m.list.SetStatusBarItemName("deployment", "deployments")
+	var items []list.Item
+	for _, b := range m.history {
+		if b.Deploy == env {
+			items = append(items, historyItem{build: b})
+		}
+	}
+	cmd := m.list.SetItems(items)
+	m.list.Select(0)
+	return cmd
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if kmsg, ok := msgin.(tea.KeyMsg); ok && !m.IsFiltering() {
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(kmsg, prev, keymap.Select):
+			switch item := m.list.SelectedItem().(type) {
+			case envItem:
+				return m, m.showHistory(item.env)
+			case historyItem:
+				return m, func() tea.Msg {
+					return msg.BuildSelectedMsg{Build: item.build}
+				}
+			}
+
+		case keymap.Matches(kmsg, prev, keymap.Back):
+			if m.list.FilterState() == list.Unfiltered && m.env != "" {
+				return m, m.showEnvs()
+			}
+
+		case keymap.Matches(kmsg, prev, keymap.Top):
+			m.list.Select(0)
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Bottom):
+			m.list.Select(len(m.list.Items()) - 1)
+			return m, nil
+
+		case keymap.IsPrefix(kmsg, keymap.Deployments):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = kmsg.String()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msgin)
+	return m, cmd
+}
+
+func (m Model) View() string {
+	if !m.loaded {
+		return styles.AppStyle.Render("Loading deployments...")
+	}
+	if m.err != nil {
+		return styles.AppStyle.Render(fmt.Sprintf("Error loading deployments: %v", m.err))
+	}
+	if len(m.envs) == 0 {
+		return styles.AppStyle.Render("No deployments found. Promote a build to see it here.")
+	}
+	title := "Environments"
+	if m.env != "" {
+		title = "History: " + m.env
+	}
+	header := styles.HeaderStyle.Render(title)
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.list.View())
+}
+
+func (m Model) IsFiltering() bool {
+	return m.list.FilterState() == list.Filtering
+}
+
+// InHistory reports whether an environment's history is shown, in which
+// case esc returns to the overview rather than leaving the view
+func (m Model) InHistory() bool {
+	return m.env != ""
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.list.SetSize(w, h-1) // Account for header
+}
+
+// SelectedBuild returns the highlighted deployment build
+func (m Model) SelectedBuild() *drone.Build {
+	switch item := m.list.SelectedItem().(type) {
+	case envItem:
+		return item.current
+	case historyItem:
+		return item.build
+	}
+	return nil
+}
+
+func deployedBy(b *drone.Build) string {
+	who := b.Sender
+	if who == "" {
+		who = b.Author
+	}
+	when := b.Finished
+	if when == 0 {
+		when = b.Created
+	}
+	verb := "promoted"
+	if b.Event == "rollback" {
+		verb = "rolled back"
+	}
+	return fmt.Sprintf("%s by %s %s", verb, who, timefmt.Ago(when))
+}
@@ -0,0 +1,259 @@
+package form
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Status tells the owner of a form whether the user is done with it
+type Status int
+
+const (
+	Editing Status = iota
+	Submitted
+	Canceled
+)
+
+// Field is a single labelled input. Use Text or Toggle to create one.
+type Field struct {
+	Key      string
+	Label    string
+	input    textinput.Model
+	toggle   bool
+	checked  bool
+	readOnly bool
+	masked   bool
+}
+
+// Text creates a free-text field
+func Text(key, label, value, placeholder string) Field {
+	in := textinput.New()
+	in.Prompt = ""
+	in.Placeholder = placeholder
+	in.SetValue(value)
+	return Field{Key: key, Label: label, input: in}
+}
+
+// Masked creates a write-only text field whose input is never echoed. Its
+// value is returned untrimmed.
+func Masked(key, label, placeholder string) Field {
+	in := textinput.New()
+	in.Prompt = ""
+	in.Placeholder = placeholder
+	in.EchoMode = textinput.EchoPassword
+	in.EchoCharacter = '•'
+	return Field{Key: key, Label: label, input: in, masked: true}
+}
+
+// Toggle creates a boolean field switched with space
+func Toggle(key, label string, checked bool) Field {
+	return Field{Key: key, Label: label, toggle: true, checked: checked}
+}
+
+// ReadOnly shows the field's value without letting it be edited
+func (f Field) ReadOnly() Field {
+	f.readOnly = true
+	return f
+}
+
+type Model struct {
+	title  string
+	fields []Field
+	focus  int
+	status Status
+	err    string
+	width  int
+}
+
+func New(title string, fields ...Field) Model {
+	m := Model{title: title, fields: fields}
+	m.focus = m.nextEditable(-1, 1)
+	m.applyFocus()
+	return m
+}
+
+// Init starts the cursor blinking in the focused field
+func (m Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if m.status != Editing {
+		return m, nil
+	}
+	kmsg, ok := msgin.(tea.KeyMsg)
+	if !ok {
+		return m.updateInput(msgin)
+	}
+
+	switch {
+	case keymap.Matches(kmsg, "", keymap.Back):
+		m.status = Canceled
+		return m, nil
+
+	case keymap.Matches(kmsg, "", keymap.FormSubmit):
+		m.status = Submitted
+		return m, nil
+
+	case keymap.Matches(kmsg, "", keymap.FormAdvance):
+		// enter moves through the form and submits from the last field
+		next := m.nextEditable(m.focus, 1)
+		if next <= m.focus {
+			m.status = Submitted
+			return m, nil
+		}
+		m.focus = next
+		m.applyFocus()
+		return m, nil
+
+	case keymap.Matches(kmsg, "", keymap.FormNext):
+		m.focus = m.nextEditable(m.focus, 1)
+		m.applyFocus()
+		return m, nil
+
+	case keymap.Matches(kmsg, "", keymap.FormPrev):
+		m.focus = m.nextEditable(m.focus, -1)
+		m.applyFocus()
+		return m, nil
+
+	case keymap.Matches(kmsg, "", keymap.FormToggle):
+		if f := &m.fields[m.focus]; f.toggle {
+			f.checked = !f.checked
+			m.err = ""
+			return m, nil
+		}
+	}
+
+	return m.updateInput(msgin)
+}
+
+func (m Model) updateInput(msgin tea.Msg) (Model, tea.Cmd) {
+	if len(m.fields) == 0 || m.fields[m.focus].toggle {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.fields[m.focus].input, cmd = m.fields[m.focus].input.Update(msgin)
+	if _, ok := msgin.(tea.KeyMsg); ok {
+		m.err = ""
+	}
+	return m, cmd
+}
+
+// nextEditable returns the index of the next editable field from i in
+// direction dir, wrapping around
+func (m Model) nextEditable(i, dir int) int {
+	n := len(m.fields)
+	for step := 1; step <= n; step++ {
+		j := ((i+dir*step)%n + n) % n
+		if !m.fields[j].readOnly {
+			return j
+		}
+	}
+	return 0
+}
+
+func (m *Model) applyFocus() {
+	for i := range m.fields {
+		if i == m.focus && !m.fields[i].toggle {
+			m.fields[i].input.Focus()
+		} else {
+			m.fields[i].input.Blur()
+		}
+	}
+}
+
+func (m Model) View() string {
+	labelWidth := 0
+	for _, f := range m.fields {
+		if w := lipgloss.Width(f.Label); w > labelWidth {
+			labelWidth = w
+		}
+	}
+
+	focused := styles.HeaderStyle
+	label := lipgloss.NewStyle().Width(labelWidth + 2)
+
+	var sb strings.Builder
+	sb.WriteString(styles.TitleStyle.Render(m.title))
+	sb.WriteString("\n")
+	for i, f := range m.fields {
+		cursor := "  "
+		l := label.Render(f.Label)
+		if i == m.focus {
+			cursor = focused.Render("│ ")
+			l = label.Inherit(focused).Render(f.Label)
+		}
+		var value string
+		switch {
+		case f.toggle:
+			box := "[ ]"
+			if f.checked {
+				box = "[x]"
+			}
+			value = box
+		case f.readOnly:
+			value = styles.HelpStyle.Render(f.input.Value())
+		default:
+			value = f.input.View()
+		}
+		sb.WriteString(fmt.Sprintf("%s%s%s\n", cursor, l, value))
+	}
+	if m.err != "" {
+		sb.WriteString("\n" + styles.StatusFailure.Render(m.err) + "\n")
+	}
+	sb.WriteString("\n" + styles.HelpStyle.Render(keymap.Join(
+		keymap.Hint("move", keymap.FormNext, keymap.FormPrev),
+		keymap.Help(keymap.FormToggle, keymap.FormAdvance, keymap.FormSubmit),
+		keymap.Hint("cancel", keymap.Back),
+	)))
+	return styles.AppStyle.Render(sb.String())
+}
+
+// Status reports whether the form was submitted or canceled. A submitted
+// form can be reopened with Reopen after a failed save.
+func (m Model) Status() Status {
+	return m.status
+}
+
+// Reopen returns a submitted form to editing with err shown under the
+// fields
+func (m *Model) Reopen(err string) {
+	m.status = Editing
+	m.err = err
+}
+
+// Value returns the trimmed text of the field with the given key
+func (m Model) Value(key string) string {
+	for _, f := range m.fields {
+		if f.Key == key {
+			if f.masked {
+				return f.input.Value()
+			}
+			return strings.TrimSpace(f.input.Value())
+		}
+	}
+	return ""
+}
+
+// Checked returns the state of the toggle with the given key
+func (m Model) Checked(key string) bool {
+	for _, f := range m.fields {
+		if f.Key == key {
+			return f.checked
+		}
+	}
+	return false
+}
+
+func (m *Model) SetWidth(w int) {
+	m.width = w
+	for i := range m.fields {
+		m.fields[i].input.Width = w / 2
+	}
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/client"
+	"github.com/arch-err/drone-tui/internal/tui/buildref"
+	"github.com/arch-err/drone-tui/internal/tui/logs"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drone/drone-go/drone"
+)
+
+// gotoPages limits how many pages of builds are searched for a SHA
+const gotoPages = 10
+
+// buildFoundMsg is the build a go to build reference led to
+type buildFoundMsg struct {
+	repoSlug string
+	build    *drone.Build
+	err      error
+}
+
+// goToBuild looks up the build req refers to in the selected repo
+func (m Model) goToBuild(req msg.GoToBuildMsg) (Model, tea.Cmd) {
+	if m.selectedRepo == nil {
+		return m, nil
+	}
+	ref, err := req.Ref.Resolve(req.From)
+	if err != nil {
+		return m.setFlash(err.Error())
+	}
+	m, flashCmd := m.setFlash(fmt.Sprintf("Looking up %s...", ref))
+	return m, tea.Batch(flashCmd, m.findBuildCmd(ref))
+}
+
+func (m Model) findBuildCmd(ref buildref.Ref) tea.Cmd {
+	repo := m.selectedRepo
+	var loaded []*drone.Build
+	if m.buildListRepo == repo.Slug {
+		loaded = m.buildList.Builds()
+	}
+	return func() tea.Msg {
+		number := ref.Number
+		if ref.SHA != "" {
+			found, err := m.findBySHA(repo, ref.SHA, loaded)
+			if err != nil {
+				return buildFoundMsg{repoSlug: repo.Slug, err: err}
+			}
+			number = found.Number
+		}
+		// Listed builds lack their stages, so fetch the build in full
+		build, err := m.client.GetBuild(repo.Namespace, repo.Name, int(number))
+		if err != nil {
+			err = fmt.Errorf("build #%d: %w", number, err)
+		}
+		return buildFoundMsg{repoSlug: repo.Slug, build: build, err: err}
+	}
+}
+
+// findBySHA returns the newest build of a commit starting with sha, looking
+// through the loaded builds before paging through the server's
+func (m Model) findBySHA(repo *drone.Repo, sha string, loaded []*drone.Build) (*drone.Build, error) {
+	if b := matchSHA(loaded, sha); b != nil {
+		return b, nil
+	}
+	for page := 1; page <= gotoPages; page++ {
+		builds, err := m.client.ListBuilds(repo.Namespace, repo.Name, client.ListOptions{Page: page})
+		if err != nil {
+			return nil, err
+		}
+		if b := matchSHA(builds, sha); b != nil {
+			return b, nil
+		}
+		if len(builds) == 0 {
+			break
+		}
+	}
+	return nil, fmt.Errorf("no build of commit %s in the last %d pages", sha, gotoPages)
+}
+
+func matchSHA(builds []*drone.Build, sha string) *drone.Build {
+	for _, b := range builds {
+		if strings.HasPrefix(strings.ToLower(b.After), sha) {
+			return b
+		}
+	}
+	return nil
+}
+
+// openFoundBuild shows the logs of the build a go to build led to
+func (m Model) openFoundBuild(found buildFoundMsg) (Model, tea.Cmd) {
+	if m.selectedRepo == nil || m.selectedRepo.Slug != found.repoSlug || !isPage(m.state) {
+		// The user moved on while the build was looked up
+		return m, nil
+	}
+	if found.err != nil {
+		return m.setFlash(found.err.Error())
+	}
+	m.flash = ""
+	m.push()
+	m.selectedBuild = found.build
+	m.logViewer = logs.New(found.build, m.width, m.height-1) // Account for statusbar
+	m.state = stateLogViewer
+	return m, m.loadAllLogsCmd(found.build)
+}
@@ -0,0 +1,110 @@
+// Package help is the full-screen overlay listing every key binding,
+// grouped by the screen it applies to.
+package help
+
+import (
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// extra lists what isn't in the keymap: the mouse
+var extra = []keymap.Section{
+	{Title: "Mouse", Entries: []keymap.Entry{
+		{Keys: "wheel", Desc: "scroll logs, panes and this help"},
+		{Keys: "click breadcrumb", Desc: "go back to that page"},
+	}},
+}
+
+type Model struct {
+	scope      keymap.Scope
+	viewport   viewport.Model
+	width      int
+	height     int
+	pendingKey string
+}
+
+// New lists the bindings with those of scope, the screen the overlay was
+// opened from, first
+func New(scope keymap.Scope, width, height int) Model {
+	m := Model{
+		scope:    scope,
+		viewport: viewport.New(width, height-2), // Account for separator + help line
+		width:    width,
+		height:   height,
+	}
+	m.viewport.SetContent(m.renderContent())
+	return m
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if kmsg, ok := msgin.(tea.KeyMsg); ok {
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(kmsg, prev, keymap.Top):
+			m.viewport.GotoTop()
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Bottom):
+			m.viewport.GotoBottom()
+			return m, nil
+
+		case keymap.IsPrefix(kmsg, keymap.HelpView):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = kmsg.String()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msgin)
+	return m, cmd
+}
+
+func (m Model) View() string {
+	help := styles.HelpStyle.Render(keymap.Join(
+		"↑/↓: scroll",
+		keymap.Hint("top/bottom", keymap.Top, keymap.Bottom),
+		keymap.Hint("close", keymap.ShowHelp, keymap.Back),
+	))
+	return lipgloss.JoinVertical(lipgloss.Left, m.viewport.View(), "", help)
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.viewport.Width = w
+	m.viewport.Height = h - 2 // Account for separator + help line
+	m.viewport.SetContent(m.renderContent())
+}
+
+func (m Model) renderContent() string {
+	sections := append(keymap.Sections(m.scope), extra...)
+
+	keyWidth := 0
+	for _, s := range sections {
+		for _, e := range s.Entries {
+			keyWidth = max(keyWidth, lipgloss.Width(e.Keys))
+		}
+	}
+	keyStyle := styles.AccentStyle.Width(keyWidth + 2)
+
+	var sb strings.Builder
+	sb.WriteString(styles.TitleStyle.Render("Key Bindings") + "\n")
+	for i, s := range sections {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(styles.HeaderStyle.Render(s.Title) + "\n")
+		for _, e := range s.Entries {
+			sb.WriteString("  " + keyStyle.Render(e.Keys) + e.Desc + "\n")
+		}
+	}
+	return styles.AppStyle.Render(strings.TrimRight(sb.String(), "\n"))
+}
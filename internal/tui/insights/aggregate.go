@@ -0,0 +1,132 @@
+package insights
+
+import (
+	"sort"
+	"time"
+
+	"github.com/drone/drone-go/drone"
+)
+
+// StepStats sums up the runs of one step across builds
+type StepStats struct {
+	Name   string
+	Runs   int
+	Passed int
+	Failed int
+	// Flaky counts the restarts where the step passed after failing in the
+	// restarted build
+	Flaky int
+	// LastFlaky is the latest such restart: the failed build and the
+	// restart where it passed
+	LastFlaky [2]int64
+	// LastFailed is the newest build where the step failed
+	LastFailed *drone.Build
+
+	P50, P90, Max time.Duration
+}
+
+// PassRate is the share of passed runs among those that passed or failed
+func (s StepStats) PassRate() float64 {
+	if s.Passed+s.Failed == 0 {
+		return 0
+	}
+	return float64(s.Passed) / float64(s.Passed+s.Failed)
+}
+
+// Aggregate computes the stats of every step in builds, flaky steps first,
+// then those failing most often. Steps are told apart by stage and step
+// name, so the same step in different pipelines is counted separately.
+func Aggregate(builds []*drone.Build) []StepStats {
+	sorted := append([]*drone.Build(nil), builds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Number < sorted[j].Number })
+
+	stages := 0
+	for _, b := range sorted {
+		stages = max(stages, len(b.Stages))
+	}
+
+	index := make(map[string]int)
+	var stats []StepStats
+	durations := make(map[string][]time.Duration)
+	// The builds each step failed in, to tell when a restart passed
+	failed := make(map[stepRun]bool)
+
+	for _, b := range sorted {
+		for _, stage := range b.Stages {
+			for _, st := range stage.Steps {
+				key := stage.Name + "/" + st.Name
+				i, ok := index[key]
+				if !ok {
+					name := st.Name
+					if stages > 1 {
+						name = key
+					}
+					i = len(stats)
+					index[key] = i
+					stats = append(stats, StepStats{Name: name})
+				}
+				s := &stats[i]
+
+				switch st.Status {
+				case "success":
+					s.Runs++
+					s.Passed++
+					// A restart links back to the build it restarted
+					if b.Parent != 0 && failed[stepRun{b.Parent, key}] {
+						s.Flaky++
+						s.LastFlaky = [2]int64{b.Parent, b.Number}
+					}
+				case "failure", "error":
+					s.Runs++
+					s.Failed++
+					s.LastFailed = b
+					failed[stepRun{b.Number, key}] = true
+				default:
+					// Skipped, killed or not finished: no verdict
+					continue
+				}
+				if st.Started > 0 && st.Stopped >= st.Started {
+					durations[key] = append(durations[key], time.Duration(st.Stopped-st.Started)*time.Second)
+				}
+			}
+		}
+	}
+
+	for key, i := range index {
+		d := durations[key]
+		if len(d) == 0 {
+			continue
+		}
+		sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+		stats[i].P50 = percentile(d, 50)
+		stats[i].P90 = percentile(d, 90)
+		stats[i].Max = d[len(d)-1]
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		switch {
+		case a.Flaky != b.Flaky:
+			return a.Flaky > b.Flaky
+		case (a.Runs == 0) != (b.Runs == 0):
+			// Steps that never got a verdict go last
+			return b.Runs == 0
+		case a.PassRate() != b.PassRate():
+			return a.PassRate() < b.PassRate()
+		}
+		return a.Name < b.Name
+	})
+	return stats
+}
+
+// stepRun is a step of one build
+type stepRun struct {
+	build int64
+	key   string
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(0, rank-1)]
+}
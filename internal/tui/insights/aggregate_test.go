@@ -0,0 +1,118 @@
+package insights
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drone/drone-go/drone"
+)
+
+// build returns a build with a single stage whose steps have the given
+// statuses, each taking secs seconds
+func build(number, parent int64, secs int64, steps map[string]string) *drone.Build {
+	b := &drone.Build{Number: number, Parent: parent, After: "3d21ec5"}
+	stage := &drone.Stage{Number: 1, Name: "default"}
+	for name, status := range steps {
+		stage.Steps = append(stage.Steps, &drone.Step{Name: name, Status: status, Started: 1000, Stopped: 1000 + secs})
+	}
+	b.Stages = []*drone.Stage{stage}
+	return b
+}
+
+func find(t *testing.T, stats []StepStats, name string) StepStats {
+	t.Helper()
+	for _, s := range stats {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("no stats for %q", name)
+	return StepStats{}
+}
+
+func TestAggregate(t *testing.T) {
+	builds := []*drone.Build{
+		build(4, 0, 40, map[string]string{"build": "success", "test": "success"}),
+		build(3, 1, 30, map[string]string{"build": "success", "test": "success"}),
+		build(2, 0, 20, map[string]string{"build": "success", "test": "failure"}),
+		build(1, 0, 10, map[string]string{"build": "success", "test": "failure"}),
+		build(5, 0, 50, map[string]string{"build": "success", "test": "skipped"}),
+	}
+	stats := Aggregate(builds)
+
+	test := find(t, stats, "test")
+	if test.Runs != 4 || test.Passed != 2 || test.Failed != 2 {
+		t.Errorf("test runs = %d/%d/%d, want 4 runs, 2 passed, 2 failed", test.Runs, test.Passed, test.Failed)
+	}
+	if test.Flaky != 1 || test.LastFlaky != [2]int64{1, 3} {
+		t.Errorf("test flaky = %d %v, want 1 [1 3]", test.Flaky, test.LastFlaky)
+	}
+	if test.LastFailed == nil || test.LastFailed.Number != 2 {
+		t.Errorf("test last failed = %v, want #2", test.LastFailed)
+	}
+	if test.P50 != 20*time.Second || test.P90 != 40*time.Second || test.Max != 40*time.Second {
+		t.Errorf("test durations = %v %v %v, want 20s 40s 40s", test.P50, test.P90, test.Max)
+	}
+	if rate := test.PassRate(); rate != 0.5 {
+		t.Errorf("test pass rate = %v, want 0.5", rate)
+	}
+
+	buildStats := find(t, stats, "build")
+	if buildStats.Runs != 5 || buildStats.Flaky != 0 || buildStats.LastFailed != nil {
+		t.Errorf("build = %+v, want 5 clean runs", buildStats)
+	}
+	if stats[0].Name != "test" {
+		t.Errorf("first step = %q, want the flaky one", stats[0].Name)
+	}
+}
+
+func TestAggregateSameCommitIsNotFlaky(t *testing.T) {
+	// A push and a pull request build of the same commit aren't restarts
+	push := build(1, 0, 10, map[string]string{"test": "failure"})
+	pr := build(2, 0, 10, map[string]string{"test": "success"})
+	pr.Event = "pull_request"
+	if s := find(t, Aggregate([]*drone.Build{push, pr}), "test"); s.Flaky != 0 {
+		t.Errorf("flaky = %d, want 0", s.Flaky)
+	}
+}
+
+func TestAggregateRestartChain(t *testing.T) {
+	builds := []*drone.Build{
+		build(1, 0, 10, map[string]string{"test": "failure"}),
+		build(2, 1, 10, map[string]string{"test": "failure"}),
+		build(3, 2, 10, map[string]string{"test": "success"}),
+	}
+	s := find(t, Aggregate(builds), "test")
+	if s.Flaky != 1 || s.LastFlaky != [2]int64{2, 3} {
+		t.Errorf("flaky = %d %v, want 1 [2 3]", s.Flaky, s.LastFlaky)
+	}
+}
+
+func TestAggregateStagesNamed(t *testing.T) {
+	b := &drone.Build{Number: 1, Stages: []*drone.Stage{
+		{Name: "linux", Steps: []*drone.Step{{Name: "test", Status: "success"}}},
+		{Name: "windows", Steps: []*drone.Step{{Name: "test", Status: "failure"}}},
+	}}
+	stats := Aggregate([]*drone.Build{b})
+	if len(stats) != 2 || stats[0].Name != "windows/test" || stats[1].Name != "linux/test" {
+		t.Errorf("stats = %+v, want windows/test then linux/test", stats)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	d := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		p    int
+		want time.Duration
+	}{
+		{0, 1},
+		{50, 5},
+		{90, 9},
+		{100, 10},
+	}
+	for _, tt := range tests {
+		if got := percentile(d, tt.p); got != tt.want {
+			t.Errorf("percentile(%d) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package insights is the per-repo view of step failure statistics: how
+// often each step passes, how long it takes, and which steps are flaky,
+// failing and then passing when the build is restarted.
+package insights
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/timefmt"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drone/drone-go/drone"
+)
+
+type stepItem struct {
+	stats StepStats
+}
+
+func (i stepItem) Title() string {
+	s := i.stats
+	icon := styles.StatusSuccess.Render("✓")
+	switch {
+	case s.Runs == 0:
+		icon = styles.StatusPending.Render("○")
+	case s.Flaky > 0:
+		icon = styles.StatusRunning.Render("⚠")
+	case s.Failed > 0:
+		icon = styles.StatusFailure.Render("✗")
+	}
+	title := fmt.Sprintf("%s %s", icon, s.Name)
+	if s.Runs > 0 {
+		title += styles.HelpStyle.Render(fmt.Sprintf("  %.0f%% passed", s.PassRate()*100))
+	}
+	if s.Flaky > 0 {
+		title += styles.StatusRunning.Render(fmt.Sprintf("  flaky %d×", s.Flaky))
+	}
+	return title
+}
+
+func (i stepItem) FilterValue() string {
+	return i.stats.Name
+}
+
+func (i stepItem) Description() string {
+	s := i.stats
+	if s.Runs == 0 {
+		return "never passed or failed"
+	}
+	parts := []string{fmt.Sprintf("%d runs, %d failed", s.Runs, s.Failed)}
+	if s.Max > 0 {
+		parts = append(parts, fmt.Sprintf("p50 %s p90 %s max %s",
+			timefmt.Duration(s.P50), timefmt.Duration(s.P90), timefmt.Duration(s.Max)))
+	}
+	if s.Flaky > 0 {
+		parts = append(parts, fmt.Sprintf("last flaky #%d → #%d", s.LastFlaky[0], s.LastFlaky[1]))
+	} else if s.LastFailed != nil {
+		parts = append(parts, fmt.Sprintf("last failed #%d", s.LastFailed.Number))
+	}
+	return strings.Join(parts, " | ")
+}
+
+type Model struct {
+	list       list.Model
+	loaded     bool
+	err        error
+	builds     int
+	cached     int
+	flaky      int
+	width      int
+	pendingKey string
+}
+
+func New(width, height int) Model {
+	l := list.New(nil, styles.NewDelegate(), width, height)
+	styles.ApplyList(&l)
+	l.SetShowTitle(false) // Title shown in external statusbar instead
+	l.KeyMap = keymap.ListKeyMap()
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.SetStatusBarItemName("step", "steps")
+	m := Model{list: l, width: width}
+	m.SetSize(width, height)
+	return m
+}
+
+// SetBuilds computes the stats of builds, cached of which were read from
+// the local cache
+func (m *Model) SetBuilds(builds []*drone.Build, cached int, err error) tea.Cmd {
+	m.loaded = true
+	m.err = err
+	if err != nil {
+		return nil
+	}
+	m.builds = len(builds)
+	m.cached = cached
+	m.flaky = 0
+
+	stats := Aggregate(builds)
+	items := make([]list.Item, len(stats))
+	for i, s := range stats {
+		items[i] = stepItem{stats: s}
+		if s.Flaky > 0 {
+			m.flaky++
+		}
+	}
+	cmd := m.list.SetItems(items)
+	m.list.Select(0)
+	return cmd
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if kmsg, ok := msgin.(tea.KeyMsg); ok && !m.IsFiltering() {
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(kmsg, prev, keymap.Select):
+			if item, ok := m.list.SelectedItem().(stepItem); ok && item.stats.LastFailed != nil {
+				build := item.stats.LastFailed
+				return m, func() tea.Msg {
+					return msg.BuildSelectedMsg{Build: build}
+				}
+			}
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Top):
+			m.list.Select(0)
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Bottom):
+			m.list.Select(len(m.list.Items()) - 1)
+			return m, nil
+
+		case keymap.IsPrefix(kmsg, keymap.Insights):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = kmsg.String()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msgin)
+	return m, cmd
+}
+
+func (m Model) View() string {
+	if !m.loaded {
+		return styles.AppStyle.Render("Loading recent builds...")
+	}
+	if m.err != nil {
+		return styles.AppStyle.Render(fmt.Sprintf("Error loading builds: %v", m.err))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, m.header(), m.list.View())
+}
+
+func (m Model) header() string {
+	if m.builds == 0 {
+		return styles.HeaderStyle.Render("No finished builds")
+	}
+	summary := fmt.Sprintf("Last %d builds", m.builds)
+	if m.flaky > 0 {
+		if m.flaky == 1 {
+			summary += " · 1 flaky step"
+		} else {
+			summary += fmt.Sprintf(" · %d flaky steps", m.flaky)
+		}
+	}
+	return styles.HeaderStyle.Render(summary) +
+		styles.HelpStyle.Render(fmt.Sprintf("  %d cached · ", m.cached)+keymap.Join(
+			keymap.Hint("last failure", keymap.Select),
+			keymap.Help(keymap.Refresh),
+		))
+}
+
+// LastFailed returns the newest build where the selected step failed
+func (m Model) LastFailed() *drone.Build {
+	if item, ok := m.list.SelectedItem().(stepItem); ok {
+		return item.stats.LastFailed
+	}
+	return nil
+}
+
+// IsFiltering reports whether keys are going to the filter
+func (m Model) IsFiltering() bool {
+	return m.list.FilterState() == list.Filtering
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.list.SetSize(w, h-1) // Account for header
+}
@@ -0,0 +1,644 @@
+// Package keymap is the registry of every key the TUI responds to. Screens
+// match key presses and render their help through it, so bindings
+// overridden in the config file apply everywhere.
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Action is something a key can do. Its value is the name used for it under
+// keys in the config file.
+type Action string
+
+const (
+	// Available on every screen
+	Quit          Action = "quit"
+	Refresh       Action = "refresh"
+	OpenInBrowser Action = "open_in_browser"
+	Back          Action = "back"
+	Top           Action = "top"
+	Bottom        Action = "bottom"
+	Select        Action = "select"
+	NextTab       Action = "next_tab"
+	PrevTab       Action = "prev_tab"
+	ShowHelp      Action = "help"
+	OpenPalette   Action = "palette"
+
+	// Lists
+	ListUp       Action = "list.up"
+	ListDown     Action = "list.down"
+	ListPrevPage Action = "list.prev_page"
+	ListNextPage Action = "list.next_page"
+	ListStart    Action = "list.start"
+	ListEnd      Action = "list.end"
+	ListFilter   Action = "list.filter"
+
+	// Navigation history
+	HistoryBack    Action = "history.back"
+	HistoryForward Action = "history.forward"
+
+	// Build screens
+	BuildInfo   Action = "build_info"
+	Watch       Action = "watch"
+	WatchBranch Action = "watch_branch"
+	Pipeline    Action = "pipeline"
+	Timeline    Action = "timeline"
+	GoToBuild   Action = "goto"
+	Compare     Action = "compare"
+
+	// Log viewer
+	PrevBranchBuild Action = "logs.prev_build"
+	NextBranchBuild Action = "logs.next_build"
+
+	// Split build list
+	SplitView Action = "builds.split"
+	FocusPane Action = "builds.focus"
+
+	// Repository list
+	Dashboard      Action = "dashboard"
+	Settings       Action = "settings"
+	Sync           Action = "sync"
+	ToggleInactive Action = "repos.toggle_inactive"
+	FavoritesOnly  Action = "repos.favorites_only"
+	GroupByOrg     Action = "repos.group"
+	ToggleFavorite Action = "repos.star"
+
+	// Build list filter
+	FilterBuilds Action = "builds.filter"
+	ClearFilter  Action = "builds.clear_filter"
+	CycleStatus  Action = "builds.cycle_status"
+	CycleEvent   Action = "builds.cycle_event"
+
+	// Pipeline graph
+	PipelineUp   Action = "pipeline.up"
+	PipelineDown Action = "pipeline.down"
+
+	// Crons, secrets and settings
+	New          Action = "new"
+	Edit         Action = "edit"
+	Delete       Action = "delete"
+	Confirm      Action = "confirm"
+	RunCron      Action = "crons.run"
+	ToggleCron   Action = "crons.toggle"
+	SecretScope  Action = "secrets.scope"
+	ToggleActive Action = "settings.toggle_active"
+	Repair       Action = "settings.repair"
+	Chown        Action = "settings.chown"
+
+	// Command palette
+	PaletteUp   Action = "palette.up"
+	PaletteDown Action = "palette.down"
+
+	// Forms
+	FormNext    Action = "form.next"
+	FormPrev    Action = "form.prev"
+	FormAdvance Action = "form.advance"
+	FormSubmit  Action = "form.submit"
+	FormToggle  Action = "form.toggle"
+)
+
+// Scope is a screen or input that keys are matched in. Two actions may only
+// share a key when no scope has both.
+type Scope string
+
+const (
+	Repos       Scope = "repos"
+	Dash        Scope = "dashboard"
+	Builds      Scope = "builds"
+	Branches    Scope = "branches"
+	Deployments Scope = "deployments"
+	Crons       Scope = "crons"
+	Secrets     Scope = "secrets"
+	RepoConfig  Scope = "settings"
+	Logs        Scope = "logs"
+	Info        Scope = "build info"
+	Graph       Scope = "pipeline"
+	Times       Scope = "timeline"
+	Preview     Scope = "log preview"
+	Diff        Scope = "compare"
+	Insights    Scope = "insights"
+	Form        Scope = "form"
+	BuildFilter Scope = "build filter"
+	GoToPrompt  Scope = "go to build"
+	HelpView    Scope = "help"
+	Palette     Scope = "palette"
+)
+
+// screens are the scopes where nothing is being typed, so global keys apply
+var screens = []Scope{Repos, Dash, Builds, Branches, Deployments, Crons, Secrets, RepoConfig, Logs, Info, Graph, Times, Preview, Diff, Insights}
+
+// scopes is every scope in the order the help overlay lists them
+var scopes = append(screens, Form, BuildFilter, GoToPrompt, Palette, HelpView)
+
+// lists are the screens built on a list, which takes the list keys
+var lists = []Scope{Repos, Dash, Builds, Branches, Deployments, Crons, Secrets, Insights}
+
+// repoTabs are the screens with the per-repo tab bar
+var repoTabs = []Scope{Builds, Branches, Deployments, Crons, Secrets, RepoConfig, Insights}
+
+type binding struct {
+	action Action
+	keys   []string
+	help   string
+	scopes []Scope
+}
+
+// defaults lists every action in the order it's documented. Keys holding a
+// space are two-key sequences, like "g g".
+var defaults = []binding{
+	{Quit, []string{"q", "ctrl+c"}, "quit", append(screens, HelpView)},
+	{Refresh, []string{"r"}, "refresh", screens},
+	{OpenInBrowser, []string{"g x"}, "open in browser", screens},
+	{Back, []string{"esc"}, "back", append(screens, Form, BuildFilter, GoToPrompt, Palette, HelpView)},
+	{Top, []string{"g g"}, "top", append(screens, HelpView)},
+	{Bottom, []string{"G"}, "bottom", append(screens, HelpView)},
+	{Select, []string{"enter"}, "open", []Scope{Repos, Dash, Builds, Branches, Deployments, Crons, Secrets, RepoConfig, Insights, Graph, BuildFilter, GoToPrompt, Palette}},
+	{NextTab, []string{"tab"}, "next tab", append(repoTabs, Logs, Graph, Preview, Diff)},
+	{PrevTab, []string{"shift+tab"}, "previous tab", append(repoTabs, Logs, Graph, Preview, Diff)},
+	{ShowHelp, []string{"?"}, "help", append(screens, HelpView)},
+	{OpenPalette, []string{":", "ctrl+p"}, "command palette", screens},
+	{ListUp, []string{"up", "k"}, "up", lists},
+	{ListDown, []string{"down", "j"}, "down", lists},
+	{ListPrevPage, []string{"left", "h", "pgup"}, "previous page", lists},
+	{ListNextPage, []string{"right", "l", "pgdown"}, "next page", lists},
+	{ListStart, []string{"home"}, "first item", lists},
+	{ListEnd, []string{"end"}, "last item", lists},
+	{ListFilter, []string{"/"}, "filter", lists},
+	{HistoryBack, []string{"[", "alt+left"}, "history back", screens},
+	{HistoryForward, []string{"]", "alt+right"}, "history forward", screens},
+
+	{BuildInfo, []string{"i"}, "info", []Scope{Builds, Branches, Deployments, Crons, Logs, Info}},
+	{Watch, []string{"w"}, "watch build", []Scope{Builds, Branches, Deployments, Logs}},
+	{WatchBranch, []string{"W"}, "watch branch", []Scope{Builds, Branches, Deployments, Logs}},
+	{Pipeline, []string{"p"}, "pipeline", []Scope{Logs, Graph}},
+	{Timeline, []string{"t"}, "timeline", []Scope{Logs, Times}},
+	{GoToBuild, []string{"#"}, "go to build", []Scope{Builds, Logs}},
+	{Compare, []string{"c"}, "compare builds", []Scope{Builds, Logs}},
+	{PrevBranchBuild, []string{"<"}, "previous build on branch", []Scope{Logs}},
+	{NextBranchBuild, []string{">"}, "next build on branch", []Scope{Logs}},
+
+	{SplitView, []string{"v"}, "split view", []Scope{Builds}},
+	{FocusPane, []string{"ctrl+w"}, "switch pane", []Scope{Builds, Preview}},
+
+	{Dashboard, []string{"d"}, "dashboard", []Scope{Repos}},
+	{Settings, []string{"s"}, "settings", []Scope{Repos}},
+	{Sync, []string{"S"}, "sync", []Scope{Repos}},
+	{ToggleInactive, []string{"a"}, "show all", []Scope{Repos}},
+	{FavoritesOnly, []string{"f"}, "favorites only", []Scope{Repos}},
+	{GroupByOrg, []string{"o"}, "group by org", []Scope{Repos}},
+	{ToggleFavorite, []string{"*"}, "star", []Scope{Repos}},
+
+	{FilterBuilds, []string{"f"}, "filter", []Scope{Builds}},
+	{ClearFilter, []string{"F"}, "clear", []Scope{Builds}},
+	{CycleStatus, []string{"s"}, "cycle status", []Scope{Builds}},
+	{CycleEvent, []string{"e"}, "cycle event", []Scope{Builds}},
+
+	{PipelineUp, []string{"up", "k"}, "up", []Scope{Graph}},
+	{PipelineDown, []string{"down", "j"}, "down", []Scope{Graph}},
+
+	{New, []string{"n"}, "new", []Scope{Crons, Secrets}},
+	{Edit, []string{"e"}, "edit", []Scope{Crons, Secrets, RepoConfig}},
+	{Delete, []string{"D"}, "delete", []Scope{Crons, Secrets}},
+	{Confirm, []string{"y"}, "confirm", []Scope{Crons, Secrets, RepoConfig}},
+	{RunCron, []string{"x"}, "run now", []Scope{Crons}},
+	{ToggleCron, []string{" "}, "enable/disable", []Scope{Crons}},
+	{SecretScope, []string{"o"}, "org/repo secrets", []Scope{Secrets}},
+	{ToggleActive, []string{"a"}, "activate/deactivate", []Scope{RepoConfig}},
+	{Repair, []string{"R"}, "repair webhook", []Scope{RepoConfig}},
+	{Chown, []string{"O"}, "take ownership", []Scope{RepoConfig}},
+
+	{PaletteUp, []string{"up", "ctrl+p"}, "up", []Scope{Palette}},
+	{PaletteDown, []string{"down", "ctrl+n"}, "down", []Scope{Palette}},
+
+	{FormNext, []string{"tab", "down"}, "next field", []Scope{Form}},
+	{FormPrev, []string{"shift+tab", "up"}, "previous field", []Scope{Form}},
+	{FormAdvance, []string{"enter"}, "next/submit", []Scope{Form}},
+	{FormSubmit, []string{"ctrl+s"}, "submit", []Scope{Form}},
+	{FormToggle, []string{" "}, "toggle", []Scope{Form}},
+}
+
+// bindings holds the active bindings, defaults overlaid with Apply
+var bindings = load(nil)
+
+func load(overrides map[string][]string) map[Action]binding {
+	m := make(map[Action]binding, len(defaults))
+	for _, b := range defaults {
+		if keys, ok := overrides[string(b.action)]; ok {
+			b.keys = keys
+		}
+		m[b.action] = b
+	}
+	return m
+}
+
+// Apply replaces the keys of the actions named in overrides. An empty list
+// unbinds an action and "space" stands for the space bar. Unknown actions,
+// malformed keys and keys claimed by two actions on the same screen are
+// errors, and leave the current bindings in place.
+func Apply(overrides map[string][]string) error {
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	normalized := make(map[string][]string, len(overrides))
+	for _, name := range names {
+		if !known(Action(name)) {
+			return fmt.Errorf("keys: unknown action %q", name)
+		}
+		keys := []string{}
+		for _, k := range overrides[name] {
+			parts := strings.Fields(k)
+			if k == " " {
+				parts = []string{"space"}
+			}
+			if len(parts) == 0 || len(parts) > 2 || len(parts) == 2 && parts[0] == "space" {
+				return fmt.Errorf("keys: %s: %q is not a key or a two-key sequence", name, k)
+			}
+			for i, p := range parts {
+				if p == "space" {
+					parts[i] = " "
+				}
+			}
+			keys = append(keys, strings.Join(parts, " "))
+		}
+		normalized[name] = keys
+	}
+
+	m := load(normalized)
+	for i, a := range defaults {
+		for _, b := range defaults[i+1:] {
+			if err := conflict(m[a.action], m[b.action]); err != nil {
+				return err
+			}
+		}
+	}
+	bindings = m
+	return nil
+}
+
+func known(a Action) bool {
+	for _, b := range defaults {
+		if b.action == a {
+			return true
+		}
+	}
+	return false
+}
+
+// conflict reports a key that a and b both claim in a scope they share. A
+// key also clashes with any sequence it starts, since that sequence could
+// never be typed.
+func conflict(a, b binding) error {
+	scope, ok := sharedScope(a, b)
+	if !ok {
+		return nil
+	}
+	for _, ka := range a.keys {
+		for _, kb := range b.keys {
+			if clash(ka, kb) {
+				return fmt.Errorf("keys: %s (%s) and %s (%s) conflict on the %s screen",
+					a.action, label(ka), b.action, label(kb), scope)
+			}
+		}
+	}
+	return nil
+}
+
+func clash(a, b string) bool {
+	switch {
+	case a == b:
+		return true
+	case isSeq(a) && !isSeq(b):
+		return first(a) == b
+	case isSeq(b) && !isSeq(a):
+		return first(b) == a
+	}
+	return false
+}
+
+func sharedScope(a, b binding) (Scope, bool) {
+	for _, sa := range a.scopes {
+		for _, sb := range b.scopes {
+			if sa == sb {
+				return sa, true
+			}
+		}
+	}
+	return "", false
+}
+
+func isSeq(k string) bool {
+	return len(k) > 1 && strings.Contains(k, " ")
+}
+
+// first returns the first key of a sequence, or k itself
+func first(k string) string {
+	if !isSeq(k) {
+		return k
+	}
+	return k[:strings.Index(k, " ")]
+}
+
+// Matches reports whether k triggers any of actions. prev is the key pressed
+// before k, used to complete sequences; pass "" when there is none.
+func Matches(k tea.KeyMsg, prev string, actions ...Action) bool {
+	s := k.String()
+	for _, a := range actions {
+		for _, bk := range bindings[a].keys {
+			if isSeq(bk) {
+				if prev != "" && bk == prev+" "+s {
+					return true
+				}
+			} else if bk == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsPrefix reports whether k starts a sequence bound in scope. Screens hold
+// on to such keys instead of acting on them.
+func IsPrefix(k tea.KeyMsg, scope Scope) bool {
+	s := k.String()
+	for _, b := range bindings {
+		if !b.in(scope) {
+			continue
+		}
+		for _, bk := range b.keys {
+			if isSeq(bk) && first(bk) == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (b binding) in(scope Scope) bool {
+	for _, s := range b.scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Actions returns the bound actions of scope in the order they're documented
+func Actions(scope Scope) []Action {
+	var actions []Action
+	for _, d := range defaults {
+		if b := bindings[d.action]; b.in(scope) && len(b.keys) > 0 {
+			actions = append(actions, d.action)
+		}
+	}
+	return actions
+}
+
+// Desc returns the description of a used in help
+func Desc(a Action) string {
+	return bindings[a].help
+}
+
+// ListKeyMap returns the keys of a list screen. Quitting and the list's own
+// help are left to the app, which binds those keys itself.
+func ListKeyMap() list.KeyMap {
+	km := list.DefaultKeyMap()
+	km.CursorUp = listBinding(ListUp)
+	km.CursorDown = listBinding(ListDown)
+	km.PrevPage = listBinding(ListPrevPage)
+	km.NextPage = listBinding(ListNextPage)
+	km.GoToStart = listBinding(ListStart)
+	km.GoToEnd = listBinding(ListEnd)
+	km.Filter = listBinding(ListFilter)
+	km.ClearFilter = listBinding(Back)
+	km.CancelWhileFiltering = listBinding(Back)
+	km.Quit = key.NewBinding(key.WithDisabled())
+	km.ForceQuit = key.NewBinding(key.WithDisabled())
+	km.ShowFullHelp = key.NewBinding(key.WithDisabled())
+	km.CloseFullHelp = key.NewBinding(key.WithDisabled())
+	return km
+}
+
+// listBinding binds a's keys for the list component, which can't take
+// sequences
+func listBinding(a Action) key.Binding {
+	var keys, labels []string
+	for _, k := range bindings[a].keys {
+		if !isSeq(k) {
+			keys = append(keys, k)
+			labels = append(labels, label(k))
+		}
+	}
+	if len(keys) == 0 {
+		return key.NewBinding(key.WithDisabled())
+	}
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(labels, "/"), bindings[a].help))
+}
+
+// keyTypes maps key names such as "enter" back to their type
+var keyTypes = func() map[string]tea.KeyType {
+	m := map[string]tea.KeyType{}
+	for t := tea.KeyType(-128); t < 128; t++ {
+		if name := t.String(); name != "" && t != tea.KeyRunes {
+			if _, ok := m[name]; !ok {
+				m[name] = t
+			}
+		}
+	}
+	return m
+}()
+
+// Press returns the key presses that trigger a through its first key: one,
+// or two for a sequence. It returns nil when a is unbound.
+func Press(a Action) []tea.KeyMsg {
+	keys := bindings[a].keys
+	if len(keys) == 0 {
+		return nil
+	}
+	k := keys[0]
+	if isSeq(k) {
+		return []tea.KeyMsg{press(first(k)), press(k[len(first(k))+1:])}
+	}
+	return []tea.KeyMsg{press(k)}
+}
+
+func press(k string) tea.KeyMsg {
+	key := tea.Key{}
+	if rest, ok := strings.CutPrefix(k, "alt+"); ok && rest != "" {
+		key.Alt, k = true, rest
+	}
+	if t, ok := keyTypes[k]; ok {
+		key.Type = t
+	} else {
+		key.Type, key.Runes = tea.KeyRunes, []rune(k)
+	}
+	return tea.KeyMsg(key)
+}
+
+// Label returns how the first key of a is shown in help, e.g. "gg"
+func Label(a Action) string {
+	keys := bindings[a].keys
+	if len(keys) == 0 {
+		return ""
+	}
+	return label(keys[0])
+}
+
+func label(k string) string {
+	switch k {
+	case " ":
+		return "space"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	}
+	if isSeq(k) {
+		return label(first(k)) + label(k[len(first(k))+1:])
+	}
+	return k
+}
+
+// Hint renders "keys: desc" for one or more actions sharing a description,
+// e.g. Hint("top/bottom", Top, Bottom) gives "gg/G: top/bottom". Unbound
+// actions are left out, and it returns "" when none are bound.
+func Hint(desc string, actions ...Action) string {
+	var labels []string
+	for _, a := range actions {
+		if l := Label(a); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	return strings.Join(labels, "/") + ": " + desc
+}
+
+// Help renders the hint for each action with its default description, joined
+// like the rest of the help lines
+func Help(actions ...Action) string {
+	hints := make([]string, 0, len(actions))
+	for _, a := range actions {
+		hints = append(hints, Hint(bindings[a].help, a))
+	}
+	return Join(hints...)
+}
+
+// Join joins hints with " · ", skipping empty ones
+func Join(hints ...string) string {
+	var parts []string
+	for _, h := range hints {
+		if h != "" {
+			parts = append(parts, h)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Entry is one action in the help overlay
+type Entry struct {
+	Keys string // every key bound to the action, e.g. "q/ctrl+c"
+	Desc string
+}
+
+// Section is a group of entries in the help overlay
+type Section struct {
+	Title   string
+	Entries []Entry
+}
+
+// Sections lists the bound actions for the help overlay: first the global
+// ones, available on every screen, and the list keys, then those of each
+// scope, starting with current. Unbound actions and empty scopes are left out.
+func Sections(current Scope) []Section {
+	global := Section{Title: "Global"}
+	for _, d := range defaults {
+		if b := bindings[d.action]; b.global() && len(b.keys) > 0 {
+			global.Entries = append(global.Entries, b.entry())
+		}
+	}
+	listKeys := Section{Title: "Lists"}
+	for _, d := range defaults {
+		if b := bindings[d.action]; b.onLists() && len(b.keys) > 0 {
+			listKeys.Entries = append(listKeys.Entries, b.entry())
+		}
+	}
+	sections := []Section{global}
+	if len(listKeys.Entries) > 0 {
+		sections = append(sections, listKeys)
+	}
+
+	order := []Scope{current}
+	for _, s := range scopes {
+		if s != current {
+			order = append(order, s)
+		}
+	}
+	for _, scope := range order {
+		section := Section{Title: title(scope)}
+		for _, d := range defaults {
+			if b := bindings[d.action]; b.in(scope) && !b.global() && !b.onLists() && len(b.keys) > 0 {
+				section.Entries = append(section.Entries, b.entry())
+			}
+		}
+		if len(section.Entries) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// global reports whether b applies on every screen
+func (b binding) global() bool {
+	for _, s := range screens {
+		if !b.in(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// onLists reports whether b is a list key, applying on exactly the list
+// screens
+func (b binding) onLists() bool {
+	if len(b.scopes) != len(lists) {
+		return false
+	}
+	for _, s := range lists {
+		if !b.in(s) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b binding) entry() Entry {
+	labels := make([]string, 0, len(b.keys))
+	for _, k := range b.keys {
+		labels = append(labels, label(k))
+	}
+	return Entry{Keys: strings.Join(labels, "/"), Desc: b.help}
+}
+
+// title capitalizes scope for use as a section title
+func title(scope Scope) string {
+	s := string(scope)
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
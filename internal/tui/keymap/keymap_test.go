@@ -0,0 +1,110 @@
+package keymap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestDefaultsDontConflict(t *testing.T) {
+	t.Cleanup(func() { bindings = load(nil) })
+	if err := Apply(nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		wantErr   string
+	}{
+		{"rebind", map[string][]string{"compare": {"C"}}, ""},
+		{"unbind", map[string][]string{"compare": {}}, ""},
+		{"sequence", map[string][]string{"compare": {"g c"}}, ""},
+		{"same key on other screens", map[string][]string{"settings.chown": {"c"}}, ""},
+		{"space", map[string][]string{"crons.toggle": {"space"}}, ""},
+		{"unknown action", map[string][]string{"comapre": {"c"}}, "unknown action"},
+		{"three keys", map[string][]string{"compare": {"g c x"}}, "not a key or a two-key sequence"},
+		{"empty key", map[string][]string{"compare": {""}}, "not a key or a two-key sequence"},
+		{"same key", map[string][]string{"compare": {"r"}}, "conflict"},
+		{"key starting a sequence", map[string][]string{"compare": {"g"}}, "conflict"},
+		{"sequence starting with a key", map[string][]string{"compare": {"r x"}}, "conflict"},
+		{"two rebinds", map[string][]string{"compare": {"C"}, "builds.clear_filter": {"C"}}, "conflict"},
+		{"list key", map[string][]string{"list.next_page": {"d"}}, "conflict"},
+		{"list key on one list", map[string][]string{"list.filter": {"f"}}, "conflict"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { bindings = load(nil) })
+			err := Apply(tt.overrides)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("Apply() = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("Apply() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyKeepsBindingsOnError(t *testing.T) {
+	t.Cleanup(func() { bindings = load(nil) })
+	if err := Apply(map[string][]string{"compare": {"r"}}); err == nil {
+		t.Fatal("conflicting bindings applied")
+	}
+	if !Matches(runes("c"), "", Compare) || Matches(runes("r"), "", Compare) {
+		t.Error("failed Apply changed the bindings")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	t.Cleanup(func() { bindings = load(nil) })
+	if err := Apply(map[string][]string{"compare": {"g c"}}); err != nil {
+		t.Fatal(err)
+	}
+	if Matches(runes("c"), "", Compare) {
+		t.Error("c alone matched the g c sequence")
+	}
+	if !Matches(runes("c"), "g", Compare) {
+		t.Error("g then c didn't match")
+	}
+	if !IsPrefix(runes("g"), Builds) {
+		t.Error("g isn't a prefix on the build list")
+	}
+}
+
+func TestListKeyMap(t *testing.T) {
+	t.Cleanup(func() { bindings = load(nil) })
+	if err := Apply(map[string][]string{"list.down": {"J", "g j"}, "list.filter": {}}); err != nil {
+		t.Fatal(err)
+	}
+	km := ListKeyMap()
+	if !key.Matches(runes("J"), km.CursorDown) || key.Matches(runes("j"), km.CursorDown) {
+		t.Errorf("cursor down keys = %v, want J", km.CursorDown.Keys())
+	}
+	if km.Filter.Enabled() {
+		t.Error("unbound filter key still enabled")
+	}
+	if km.Quit.Enabled() || km.ShowFullHelp.Enabled() {
+		t.Error("list handles keys the app binds")
+	}
+}
+
+func TestSectionsListKeys(t *testing.T) {
+	sections := Sections(Builds)
+	if len(sections) < 3 || sections[1].Title != "Lists" || sections[2].Title != "Builds" {
+		t.Fatalf("sections = %v, want Global, Lists, then Builds", sections)
+	}
+	for _, e := range sections[2].Entries {
+		if e.Keys == "/" {
+			t.Errorf("list key %q repeated under Builds", e.Keys)
+		}
+	}
+}
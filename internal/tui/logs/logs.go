@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/arch-err/drone-tui/internal/tui/buildref"
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
 	"github.com/arch-err/drone-tui/internal/tui/msg"
 	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/timefmt"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -18,19 +21,29 @@ type stepTab struct {
 	stageNum int
 	stepNum  int
 	status   string
+	started  int64
+	stopped  int64
 	content  string
 	loaded   bool
 }
 
 type Model struct {
-	tabs          []stepTab
-	activeTab     int
-	viewport      viewport.Model
-	spinner       spinner.Model
-	width         int
-	height        int
-	buildNum      int64
-	pendingGCount int
+	tabs       []stepTab
+	activeTab  int
+	viewport   viewport.Model
+	spinner    spinner.Model
+	width      int
+	height     int
+	buildNum   int64
+	pendingKey string
+
+	// A preview sits beside the build list, with its step tabs on top
+	preview bool
+	focused bool
+
+	// Go to build prompt, shown in place of the help line
+	jump    buildref.Prompt
+	jumping bool
 }
 
 func New(build *drone.Build, width, height int) Model {
@@ -42,6 +55,8 @@ func New(build *drone.Build, width, height int) Model {
 				stageNum: int(stage.Number),
 				stepNum:  int(step.Number),
 				status:   step.Status,
+				started:  step.Started,
+				stopped:  step.Stopped,
 			})
 		}
 	}
@@ -68,47 +83,69 @@ func New(build *drone.Build, width, height int) Model {
 	return m
 }
 
+// NewPreview creates a log viewer for the split build list. It draws its
+// own step tabs instead of leaving them to the statusbar.
+func NewPreview(build *drone.Build, width, height int) Model {
+	m := New(build, width, height)
+	m.preview = true
+	return m
+}
+
+// SetFocused tells a preview whether it has the keyboard
+func (m *Model) SetFocused(focused bool) {
+	m.focused = focused
+}
+
 func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msgin := msgin.(type) {
 	case tea.KeyMsg:
-		switch msgin.String() {
-		case "tab":
+		if m.jumping {
+			return m.updateJump(msgin)
+		}
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(msgin, prev, keymap.GoToBuild) && !m.preview:
+			var cmd tea.Cmd
+			m.jump, cmd = buildref.NewPrompt(m.width)
+			m.jumping = true
+			return m, cmd
+
+		case keymap.Matches(msgin, prev, keymap.NextTab):
 			if len(m.tabs) > 0 {
 				m.activeTab = (m.activeTab + 1) % len(m.tabs)
 				m.updateViewportContent()
 			}
 			return m, nil
 
-		case "shift+tab":
+		case keymap.Matches(msgin, prev, keymap.PrevTab):
 			if len(m.tabs) > 0 {
 				m.activeTab = (m.activeTab - 1 + len(m.tabs)) % len(m.tabs)
 				m.updateViewportContent()
 			}
 			return m, nil
 
-		case "g":
-			// Vim binding: gg to go to top
-			m.pendingGCount++
-			if m.pendingGCount == 2 {
-				m.viewport.GotoTop()
-				m.pendingGCount = 0
-			}
+		case keymap.Matches(msgin, prev, keymap.Top):
+			m.viewport.GotoTop()
 			return m, nil
 
-		case "G":
-			// Vim binding: G to go to bottom
+		case keymap.Matches(msgin, prev, keymap.Bottom):
 			m.viewport.GotoBottom()
-			m.pendingGCount = 0
 			return m, nil
 
-		default:
-			// Reset pending g count on any other key
-			m.pendingGCount = 0
+		case keymap.IsPrefix(msgin, keymap.Logs):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = msgin.String()
+			return m, nil
 		}
 
 	case msg.LogsLoadedMsg:
+		if msgin.BuildNum != m.buildNum {
+			return m, nil
+		}
 		for i, tab := range m.tabs {
 			if tab.stageNum == msgin.StageNum && tab.stepNum == msgin.StepNum {
 				if msgin.Err != nil {
@@ -160,40 +197,74 @@ func (m Model) View() string {
 		return styles.AppStyle.Render("No steps found in this build.")
 	}
 
-	help := styles.HelpStyle.Render("tab/shift+tab: switch · ↑/↓: scroll · gg/G: top/bottom · r: refresh · gx: open in browser · esc: back")
+	if m.preview {
+		return m.previewView()
+	}
+
+	help := styles.HelpStyle.Render(keymap.Join(
+		keymap.Hint("switch", keymap.NextTab, keymap.PrevTab),
+		"↑/↓: scroll",
+		keymap.Hint("top/bottom", keymap.Top, keymap.Bottom),
+		keymap.Help(keymap.BuildInfo, keymap.Pipeline, keymap.Timeline, keymap.GoToBuild, keymap.Back, keymap.ShowHelp),
+	))
+	if m.jumping {
+		help = m.jump.View()
+	}
 	return lipgloss.JoinVertical(lipgloss.Left, m.viewport.View(), "", help)
 }
 
+func (m Model) previewView() string {
+	// Drop tabs from the left until the active one fits
+	pills := m.pills()
+	start := 0
+	for start < m.activeTab && lipgloss.Width(lipgloss.JoinHorizontal(lipgloss.Top, pills[start:]...)) > m.width {
+		start++
+	}
+	tabs := lipgloss.NewStyle().MaxWidth(m.width).Render(lipgloss.JoinHorizontal(lipgloss.Top, pills[start:]...))
+
+	hint := keymap.Hint("focus logs", keymap.FocusPane)
+	if m.focused {
+		hint = keymap.Join(
+			keymap.Hint("switch", keymap.NextTab, keymap.PrevTab),
+			"↑/↓: scroll",
+			keymap.Hint("list", keymap.FocusPane, keymap.Back),
+		)
+	}
+	help := lipgloss.NewStyle().MaxWidth(m.width).Render(styles.HelpStyle.Render(hint))
+	return lipgloss.JoinVertical(lipgloss.Left, tabs, m.viewport.View(), help)
+}
+
 // RenderStatusBar renders the tab bar as a single line statusbar
 func (m Model) RenderStatusBar() string {
 	if len(m.tabs) == 0 {
 		return ""
 	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, m.pills()...)
+}
 
+// pills renders one tab per step
+func (m Model) pills() []string {
 	var parts []string
 	for i, tab := range m.tabs {
 		icon := statusIconChar(tab.status)
 
-		var label string
+		label := icon + " " + tab.name
+		if d := timefmt.Elapsed(tab.started, tab.stopped); d != "" {
+			label += " " + d
+		}
 		if !tab.loaded {
-			label = icon + " " + tab.name + " " + m.spinner.View()
-		} else {
-			label = icon + " " + tab.name
+			label += " " + m.spinner.View()
 		}
 
-		style := lipgloss.NewStyle().Padding(0, 1)
+		// Inactive tabs share the statusbar background
+		style := styles.PillStyle
 		if i == m.activeTab {
-			// Active tab: bright background
-			style = style.Background(lipgloss.Color("63")).Foreground(lipgloss.Color("231")).Bold(true)
-		} else {
-			// Inactive tab: same background as statusbar, dim foreground
-			style = style.Background(lipgloss.Color("235")).Foreground(lipgloss.Color("244"))
+			style = styles.SelectedPillStyle
 		}
 
 		parts = append(parts, style.Render(label))
 	}
-
-	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+	return parts
 }
 
 // statusIconChar returns just the icon character without styling
@@ -221,6 +292,67 @@ func (m *Model) SetSize(w, h int) {
 	m.viewport.Height = h - 2 // Account for separator + help line
 }
 
+// IsPrompting reports whether keyboard input is captured by the go to
+// build prompt
+func (m Model) IsPrompting() bool {
+	return m.jumping
+}
+
+// updateJump feeds the go to build prompt, asking the app to open the
+// build once a reference is entered
+func (m Model) updateJump(k tea.KeyMsg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.jump, cmd = m.jump.Update(k)
+	switch m.jump.Status() {
+	case buildref.Submitted:
+		m.jumping = false
+		req := msg.GoToBuildMsg{Ref: m.jump.Ref(), From: m.buildNum}
+		return m, func() tea.Msg { return req }
+	case buildref.Canceled:
+		m.jumping = false
+	}
+	return m, cmd
+}
+
+// SelectStep activates the tab for the given stage and step numbers
+func (m *Model) SelectStep(stageNum, stepNum int) {
+	for i, tab := range m.tabs {
+		if tab.stageNum == stageNum && tab.stepNum == stepNum {
+			m.activeTab = i
+			m.updateViewportContent()
+			return
+		}
+	}
+}
+
+// SelectStepNamed activates the step called name, preferring the one in
+// stage stageNum when several stages have a step of that name
+func (m *Model) SelectStepNamed(name string, stageNum int) bool {
+	match := -1
+	for i, tab := range m.tabs {
+		if tab.name != name {
+			continue
+		}
+		if match < 0 || tab.stageNum == stageNum {
+			match = i
+		}
+	}
+	if match < 0 {
+		return false
+	}
+	m.activeTab = match
+	m.updateViewportContent()
+	return true
+}
+
+// ActiveStepName returns the name of the currently active step
+func (m Model) ActiveStepName() string {
+	if m.activeTab >= 0 && m.activeTab < len(m.tabs) {
+		return m.tabs[m.activeTab].name
+	}
+	return ""
+}
+
 // ActiveStep returns the stage and step numbers of the currently active tab
 func (m Model) ActiveStep() (stageNum, stepNum int, ok bool) {
 	if m.activeTab >= 0 && m.activeTab < len(m.tabs) {
@@ -1,23 +1,198 @@
 package msg
 
-import "github.com/drone/drone-go/drone"
+import (
+	"github.com/arch-err/drone-tui/internal/client"
+	"github.com/arch-err/drone-tui/internal/tui/buildquery"
+	"github.com/arch-err/drone-tui/internal/tui/buildref"
+	"github.com/drone/drone-go/drone"
+)
 
 type ReposLoadedMsg struct {
 	Repos []*drone.Repo
 	Err   error
 }
 
+// ReposRefreshedMsg is the result of a background auto-refresh of the repo
+// list
+type ReposRefreshedMsg struct {
+	Repos []*drone.Repo
+	Err   error
+}
+
+// ReposSyncedMsg is the repo list reloaded after a sync with the remote
+type ReposSyncedMsg struct {
+	Repos []*drone.Repo
+	Err   error
+}
+
+// FavoritesChangedMsg is sent when a repo is starred or unstarred
+type FavoritesChangedMsg struct {
+	Favorites []string
+}
+
 type BuildsLoadedMsg struct {
 	Builds []*drone.Build
 	Err    error
 }
 
+// BuildsRefreshedMsg is the result of a background auto-refresh of the
+// build list
+type BuildsRefreshedMsg struct {
+	RepoSlug string
+	Branch   string
+	Builds   []*drone.Build
+	Err      error
+}
+
+// BuildQueryChangedMsg is sent when the structured build filter changes
+type BuildQueryChangedMsg struct {
+	Query buildquery.Query
+}
+
+// BranchesLoadedMsg carries the latest build of each branch
+type BranchesLoadedMsg struct {
+	RepoSlug string
+	Builds   []*drone.Build
+	Err      error
+}
+
+type BranchSelectedMsg struct {
+	Branch string
+}
+
+// DeploymentsLoadedMsg carries the latest deployment per environment and
+// the deployment builds found in recent history
+type DeploymentsLoadedMsg struct {
+	RepoSlug string
+	Latest   []*drone.Build
+	History  []*drone.Build
+	Err      error
+}
+
+// CronsLoadedMsg carries a repo's cron jobs and the newest build of each,
+// keyed by cron name
+type CronsLoadedMsg struct {
+	RepoSlug   string
+	Crons      []*drone.Cron
+	LastBuilds map[string]*drone.Build
+	Err        error
+}
+
+// InsightsLoadedMsg carries the recent finished builds of a repo with
+// their stages, Cached of which came from the local cache
+type InsightsLoadedMsg struct {
+	RepoSlug string
+	Builds   []*drone.Build
+	Cached   int
+	Err      error
+}
+
+// CronAction is a change to a cron job requested from the crons view
+type CronAction int
+
+const (
+	CronCreate CronAction = iota
+	CronUpdate
+	CronDelete
+	CronRun
+)
+
+// CronActionMsg asks the app to apply a cron change through the client.
+// Cron identifies the job; Patch is only set for updates.
+type CronActionMsg struct {
+	Action CronAction
+	Cron   *drone.Cron
+	Patch  *drone.CronPatch
+}
+
+// CronActionDoneMsg reports the outcome of a CronActionMsg. Build is the
+// build started by a run.
+type CronActionDoneMsg struct {
+	RepoSlug string
+	Action   CronAction
+	Name     string
+	Build    *drone.Build
+	Err      error
+}
+
+// SecretsLoadedMsg carries the secret names of a repo, or of its
+// organization when Org is set
+type SecretsLoadedMsg struct {
+	RepoSlug string
+	Org      bool
+	Secrets  []*drone.Secret
+	Err      error
+}
+
+// SecretScopeChangedMsg asks the app to load repo or organization secrets
+type SecretScopeChangedMsg struct {
+	Org bool
+}
+
+// SecretAction is a change to a secret requested from the secrets view
+type SecretAction int
+
+const (
+	SecretCreate SecretAction = iota
+	SecretUpdate
+	SecretDelete
+)
+
+// SecretActionMsg asks the app to apply a secret change through the
+// client. Secret carries the value for creates; Patch is only set for
+// updates.
+type SecretActionMsg struct {
+	Action SecretAction
+	Org    bool
+	Secret *drone.Secret
+	Patch  *client.SecretPatch
+}
+
+// SecretActionDoneMsg reports the outcome of a SecretActionMsg
+type SecretActionDoneMsg struct {
+	RepoSlug string
+	Action   SecretAction
+	Name     string
+	Err      error
+}
+
+// RepoAction is a change to the selected repo requested from the settings
+// view
+type RepoAction int
+
+const (
+	RepoActivate RepoAction = iota
+	RepoDeactivate
+	RepoUpdate
+	RepoRepair
+	RepoChown
+)
+
+// RepoActionMsg asks the app to apply a repo change through the client.
+// Patch is only set for updates.
+type RepoActionMsg struct {
+	RepoSlug string
+	Action   RepoAction
+	Patch    *drone.RepoPatch
+}
+
+// RepoActionDoneMsg reports the outcome of a RepoActionMsg with the repo as
+// it is now
+type RepoActionDoneMsg struct {
+	RepoSlug string
+	Action   RepoAction
+	Repo     *drone.Repo
+	Err      error
+}
+
 type BuildLoadedMsg struct {
 	Build *drone.Build
 	Err   error
 }
 
 type LogsLoadedMsg struct {
+	RepoSlug string
+	BuildNum int64
 	StepName string
 	StageNum int
 	StepNum  int
@@ -25,6 +200,16 @@ type LogsLoadedMsg struct {
 	Err      error
 }
 
+// DashboardLoadedMsg carries a copy of the repo for each candidate build,
+// with the build in Repo.Build. Scanned holds the finished builds found by
+// the per-repo scan when Scan is set.
+type DashboardLoadedMsg struct {
+	Repos   []*drone.Repo
+	Scanned []*drone.Repo
+	Scan    bool
+	Err     error
+}
+
 type RepoSelectedMsg struct {
 	Repo *drone.Repo
 }
@@ -33,8 +218,35 @@ type BuildSelectedMsg struct {
 	Build *drone.Build
 }
 
+// GoToBuildMsg asks the app to find and open the build Ref points to. From
+// is the build a relative reference counts back from.
+type GoToBuildMsg struct {
+	Ref  buildref.Ref
+	From int64
+}
+
+// CompareBuildsMsg asks the app to compare the logs of two builds of the
+// selected repo
+type CompareBuildsMsg struct {
+	Base *drone.Build
+	Head *drone.Build
+}
+
+type DashboardBuildSelectedMsg struct {
+	Repo  *drone.Repo
+	Build *drone.Build
+}
+
+type StepSelectedMsg struct {
+	StageNum int
+	StepNum  int
+}
+
 type ClearEscapeHintMsg struct{}
 
+// ClearHighlightMsg forces a redraw once change highlights have expired
+type ClearHighlightMsg struct{}
+
 type OpenBrowserMsg struct {
 	URL string
 }
@@ -0,0 +1,307 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/branches"
+	"github.com/arch-err/drone-tui/internal/tui/buildinfo"
+	"github.com/arch-err/drone-tui/internal/tui/builds"
+	"github.com/arch-err/drone-tui/internal/tui/compare"
+	"github.com/arch-err/drone-tui/internal/tui/crons"
+	"github.com/arch-err/drone-tui/internal/tui/dashboard"
+	"github.com/arch-err/drone-tui/internal/tui/deployments"
+	"github.com/arch-err/drone-tui/internal/tui/insights"
+	"github.com/arch-err/drone-tui/internal/tui/logs"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/pipeline"
+	"github.com/arch-err/drone-tui/internal/tui/secrets"
+	"github.com/arch-err/drone-tui/internal/tui/settings"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/timeline"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drone/drone-go/drone"
+)
+
+// maxHistory bounds how many pages back can return to
+const maxHistory = 50
+
+// page is a view in the navigation history. It keeps the view's model as
+// it was left, so going back restores its cursor, filter and scroll
+// position. The repo list is always live and keeps no model.
+type page struct {
+	state state
+	repo  *drone.Repo
+	build *drone.Build
+	model any
+}
+
+// isPage reports whether s is a view rather than a loading screen
+func isPage(s state) bool {
+	switch s {
+	case stateLoadingRepos, stateLoadingBuilds, stateLoadingBuild:
+		return false
+	}
+	return true
+}
+
+// current captures the view on screen as a page
+func (m Model) current() page {
+	p := page{state: m.state, repo: m.selectedRepo, build: m.selectedBuild}
+	switch m.state {
+	case stateDashboard:
+		p.model = m.dashboard
+	case stateBuildList:
+		p.model = m.buildList
+	case stateBranchList:
+		p.model = m.branchList
+	case stateDeployments:
+		p.model = m.deployments
+	case stateCrons:
+		p.model = m.crons
+	case stateSecrets:
+		p.model = m.secrets
+	case stateSettings:
+		p.model = m.settings
+	case stateInsights:
+		p.model = m.insights
+	case stateLogViewer:
+		p.model = m.logViewer
+	case stateBuildInfo:
+		p.model = m.buildInfo
+	case statePipeline:
+		p.model = m.pipeline
+	case stateTimeline:
+		p.model = m.timeline
+	case stateCompare:
+		p.model = m.comparison
+	}
+	return p
+}
+
+// push records the current view before navigating away from it. Going
+// somewhere new drops the pages ahead.
+func (m *Model) push() {
+	if !isPage(m.state) {
+		return
+	}
+	m.history = append(m.history, m.current())
+	if len(m.history) > maxHistory {
+		m.history = m.history[1:]
+	}
+	m.forward = nil
+}
+
+// back returns to the previous page, keeping the current one for forward.
+// With no history left it falls back to the repo list.
+func (m Model) back() (Model, tea.Cmd) {
+	prev := page{state: stateRepoList, repo: m.selectedRepo}
+	if n := len(m.history); n > 0 {
+		prev = m.history[n-1]
+		m.history = m.history[:n-1]
+	} else if m.state == stateRepoList {
+		return m, nil
+	}
+	if isPage(m.state) {
+		m.forward = append(m.forward, m.current())
+	}
+	return m.restore(prev)
+}
+
+// goForward returns to the page last left with back
+func (m Model) goForward() (Model, tea.Cmd) {
+	n := len(m.forward)
+	if n == 0 {
+		return m, nil
+	}
+	next := m.forward[n-1]
+	m.forward = m.forward[:n-1]
+	m.history = append(m.history, m.current())
+	return m.restore(next)
+}
+
+// backTo goes back to history[i], as if back was pressed until it showed
+func (m Model) backTo(i int) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	for len(m.history) > i {
+		m, cmd = m.back()
+	}
+	return m, cmd
+}
+
+// restore shows p with the model it was left with
+func (m Model) restore(p page) (Model, tea.Cmd) {
+	m.state = p.state
+	m.selectedRepo = p.repo
+	m.selectedBuild = p.build
+
+	switch model := p.model.(type) {
+	case dashboard.Model:
+		m.dashboard = model
+	case builds.Model:
+		m.buildList = model
+		m.buildQuery = model.Query()
+		m.buildListRepo = p.repo.Slug
+		m.buildListBranch = m.buildQuery.ServerBranch()
+	case branches.Model:
+		m.branchList = model
+	case deployments.Model:
+		m.deployments = model
+	case crons.Model:
+		m.crons = model
+	case secrets.Model:
+		m.secrets = model
+	case settings.Model:
+		m.settings = model
+	case insights.Model:
+		m.insights = model
+	case logs.Model:
+		m.logViewer = model
+	case buildinfo.Model:
+		m.buildInfo = model
+	case pipeline.Model:
+		m.pipeline = model
+	case timeline.Model:
+		m.timeline = model
+	case compare.Model:
+		m.comparison = model
+	}
+
+	// The terminal may have been resized since the page was left
+	m = m.propagateSize()
+	switch p.state {
+	case stateDashboard:
+		return m.enterDashboard()
+	case stateBuildList:
+		return m.schedulePreview()
+	}
+	return m, nil
+}
+
+// deliverLogs hands step logs to the log viewers in the history. Logs
+// still loading when the build info, graph or timeline was opened arrive
+// while the log viewer is a page back, and would otherwise never show.
+func (m Model) deliverLogs(l msg.LogsLoadedMsg) Model {
+	for _, pages := range [][]page{m.history, m.forward} {
+		for i, p := range pages {
+			viewer, ok := p.model.(logs.Model)
+			if !ok || p.repo == nil || p.repo.Slug != l.RepoSlug {
+				continue
+			}
+			pages[i].model, _ = viewer.Update(l)
+		}
+	}
+	return m
+}
+
+// shownBuild returns the build p is about, or nil for the lists. The
+// selected build lingers after leaving it, so it can't tell on its own.
+func (p page) shownBuild() *drone.Build {
+	switch p.state {
+	case stateLogViewer, statePipeline, stateTimeline:
+		return p.build
+	case stateBuildInfo:
+		if info, ok := p.model.(buildinfo.Model); ok {
+			return info.Build()
+		}
+	}
+	return nil
+}
+
+// crumb labels p for the breadcrumb, leaving out the repo and build when
+// they're the same as on prev, the page before it
+func crumb(p page, prev *page) string {
+	build := p.shownBuild()
+	sameRepo := prev != nil && prev.repo != nil && p.repo != nil && prev.repo.Slug == p.repo.Slug
+	sameBuild := sameRepo && prev.shownBuild() != nil && build != nil && prev.shownBuild().Number == build.Number
+
+	var parts []string
+	switch p.state {
+	case stateRepoList:
+		return "Repos"
+	case stateDashboard:
+		return "Dashboard"
+	case stateBuildList:
+		return p.repo.Slug
+	case stateBranchList, stateDeployments, stateCrons, stateSecrets, stateSettings, stateInsights:
+		for _, t := range repoTabs {
+			if t.state == p.state {
+				return p.repo.Slug + " " + strings.ToLower(t.label)
+			}
+		}
+	}
+	if !sameRepo && p.repo != nil {
+		parts = append(parts, p.repo.Slug)
+	}
+	if !sameBuild && build != nil {
+		parts = append(parts, fmt.Sprintf("#%d", build.Number))
+	}
+	switch p.state {
+	case stateBuildInfo:
+		parts = append(parts, "info")
+	case statePipeline:
+		parts = append(parts, "pipeline")
+	case stateTimeline:
+		parts = append(parts, "timeline")
+	case stateCompare:
+		if compared, ok := p.model.(compare.Model); ok {
+			base, head := compared.Builds()
+			parts = append(parts, fmt.Sprintf("#%d vs #%d", base.Number, head.Number))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+const (
+	crumbSeparator = " › "
+	// maxCrumbs is how many history pages the breadcrumb shows before
+	// eliding the older ones
+	maxCrumbs = 4
+)
+
+// renderBreadcrumb renders the latest pages in the history followed by the
+// current one. ends holds where each history page's crumb stops, for
+// clicks; elided pages end at -1.
+func (m Model) renderBreadcrumb() (rendered string, ends []int) {
+	ancestorStyle := styles.BarMutedStyle.UnsetPadding()
+	currentStyle := styles.BarAccentStyle.UnsetPadding()
+	separator := ancestorStyle.Render(crumbSeparator)
+
+	var sb strings.Builder
+	x := 1 // Account for padding
+	sb.WriteString(ancestorStyle.Render(" "))
+	var prev *page
+	start := max(0, len(m.history)-maxCrumbs)
+	if start > 0 {
+		sb.WriteString(ancestorStyle.Render("…") + separator)
+		x += lipgloss.Width("…" + crumbSeparator)
+		prev = &m.history[start-1]
+	}
+	for range start {
+		ends = append(ends, -1)
+	}
+	for i := start; i < len(m.history); i++ {
+		label := crumb(m.history[i], prev)
+		sb.WriteString(ancestorStyle.Render(label) + separator)
+		x += lipgloss.Width(label)
+		ends = append(ends, x)
+		x += lipgloss.Width(crumbSeparator)
+		prev = &m.history[i]
+	}
+	sb.WriteString(currentStyle.Render(crumb(m.current(), prev)) + currentStyle.Render(" "))
+	return sb.String(), ends
+}
+
+// clickBreadcrumb goes back to the history page whose crumb is at column x
+// of the statusbar
+func (m Model) clickBreadcrumb(x int) (Model, tea.Cmd, bool) {
+	_, ends := m.renderBreadcrumb()
+	for i, end := range ends {
+		if x < end {
+			m, cmd := m.backTo(i)
+			return m, cmd, true
+		}
+	}
+	return m, nil, false
+}
@@ -0,0 +1,25 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drone/drone-go/drone"
+)
+
+func TestBreadcrumbEndsWideNames(t *testing.T) {
+	m := repoListModel(1)
+	m.history = []page{
+		{state: stateRepoList},
+		{state: stateBuildList, repo: &drone.Repo{Slug: "チーム/アプリ"}},
+	}
+	m.state = stateDashboard
+	_, ends := m.renderBreadcrumb()
+	want := []int{
+		lipgloss.Width(" Repos"),
+		lipgloss.Width(" Repos" + crumbSeparator + "チーム/アプリ"),
+	}
+	if len(ends) != len(want) || ends[0] != want[0] || ends[1] != want[1] {
+		t.Errorf("crumb ends = %v, want %v", ends, want)
+	}
+}
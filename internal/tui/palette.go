@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/palette"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paletteCommands lists what the command palette offers on the current
+// screen: its key actions, then the loaded builds of the selected repo and
+// every repo to jump to
+func (m Model) paletteCommands() []palette.Command {
+	var commands []palette.Command
+	for _, a := range keymap.Actions(m.scope()) {
+		if a == keymap.OpenPalette {
+			continue
+		}
+		desc := keymap.Desc(a)
+		commands = append(commands, palette.Command{
+			Title: strings.ToUpper(desc[:1]) + desc[1:],
+			Keys:  keymap.Label(a),
+			Run:   pressCmd(a),
+		})
+	}
+
+	if m.selectedRepo != nil && m.buildListRepo == m.selectedRepo.Slug {
+		for _, build := range m.buildList.Builds() {
+			title := strings.SplitN(strings.TrimSpace(build.Message), "\n", 2)[0]
+			commands = append(commands, palette.Command{
+				Title: fmt.Sprintf("Open build #%d %s", build.Number, title),
+				Run: func() tea.Msg {
+					return msg.BuildSelectedMsg{Build: build}
+				},
+			})
+		}
+	}
+
+	for _, repo := range m.repoList.Repos() {
+		commands = append(commands, palette.Command{
+			Title: "Go to repo " + repo.Slug,
+			Run: func() tea.Msg {
+				return msg.RepoSelectedMsg{Repo: repo}
+			},
+		})
+	}
+	return commands
+}
+
+// pressCmd replays the key presses bound to a, so a command from the
+// palette is handled exactly like the key
+func pressCmd(a keymap.Action) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, k := range keymap.Press(a) {
+		cmds = append(cmds, func() tea.Msg { return k })
+	}
+	return tea.Sequence(cmds...)
+}
+
+// updatePalette handles keys while the command palette is open and runs the
+// chosen command once it closes
+func (m Model) updatePalette(k tea.KeyMsg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.palette, cmd = m.palette.Update(k)
+
+	switch m.palette.Status() {
+	case palette.Canceled:
+		m.lastKey = ""
+		m.showPalette = false
+		return m, nil
+
+	case palette.Chosen:
+		m.lastKey = ""
+		m.showPalette = false
+		if c, ok := m.palette.Selected(); ok {
+			return m, c.Run
+		}
+		return m, nil
+	}
+	return m, cmd
+}
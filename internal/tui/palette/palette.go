@@ -0,0 +1,202 @@
+// Package palette is the command palette: a fuzzy search over the actions
+// and navigation targets of the current screen.
+package palette
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Command is one entry in the palette
+type Command struct {
+	Title string
+	Keys  string // label of the key that does the same, if any
+	Run   tea.Cmd
+}
+
+// Status tells the owner of the palette whether the user is done with it
+type Status int
+
+const (
+	Choosing Status = iota
+	Chosen
+	Canceled
+)
+
+type Model struct {
+	commands []Command
+	titles   []string
+	matches  []list.Rank
+	input    textinput.Model
+	cursor   int
+	offset   int
+	status   Status
+	width    int
+	height   int
+}
+
+func New(commands []Command, width, height int) Model {
+	in := textinput.New()
+	in.Prompt = ": "
+	in.Placeholder = "type a command, repo or build"
+	in.Cursor.SetMode(cursor.CursorStatic)
+	in.Focus()
+
+	m := Model{commands: commands, input: in, width: width, height: height}
+	for _, c := range commands {
+		m.titles = append(m.titles, c.Title)
+	}
+	m.filter()
+	return m
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if m.status != Choosing {
+		return m, nil
+	}
+	kmsg, ok := msgin.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case keymap.Matches(kmsg, "", keymap.Back):
+		m.status = Canceled
+		return m, nil
+
+	case keymap.Matches(kmsg, "", keymap.Select):
+		if len(m.matches) > 0 {
+			m.status = Chosen
+		}
+		return m, nil
+
+	case keymap.Matches(kmsg, "", keymap.PaletteUp):
+		m.move(-1)
+		return m, nil
+
+	case keymap.Matches(kmsg, "", keymap.PaletteDown):
+		m.move(1)
+		return m, nil
+	}
+
+	value := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msgin)
+	if m.input.Value() != value {
+		m.filter()
+	}
+	return m, cmd
+}
+
+func (m Model) Status() Status {
+	return m.status
+}
+
+// Selected returns the highlighted command, which is the one run once the
+// palette is Chosen
+func (m Model) Selected() (Command, bool) {
+	if len(m.matches) == 0 {
+		return Command{}, false
+	}
+	return m.commands[m.matches[m.cursor].Index], true
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.move(0)
+}
+
+// filter matches the commands against the input, best match first
+func (m *Model) filter() {
+	term := strings.TrimSpace(m.input.Value())
+	if term == "" {
+		m.matches = make([]list.Rank, len(m.commands))
+		for i := range m.commands {
+			m.matches[i] = list.Rank{Index: i}
+		}
+	} else {
+		m.matches = list.DefaultFilter(term, m.titles)
+	}
+	m.cursor, m.offset = 0, 0
+}
+
+// move shifts the cursor by delta, scrolling to keep it visible
+func (m *Model) move(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.matches)-1))
+	rows := m.rows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+}
+
+// rows returns how many commands fit below the input
+func (m Model) rows() int {
+	// Account for padding, title, input and help line
+	return max(1, m.height-7)
+}
+
+func (m Model) View() string {
+	var sb strings.Builder
+	sb.WriteString(styles.TitleStyle.Render("Command Palette") + "\n")
+	sb.WriteString(m.input.View() + "\n\n")
+
+	if len(m.matches) == 0 {
+		sb.WriteString(styles.MutedStyle.Render("No matching commands"))
+	}
+	// Account for padding and the cursor
+	width := max(10, m.width-6)
+	end := min(len(m.matches), m.offset+m.rows())
+	for i := m.offset; i < end; i++ {
+		match := m.matches[i]
+		c := m.commands[match.Index]
+
+		cursor := "  "
+		base := lipgloss.NewStyle()
+		if i == m.cursor {
+			cursor = styles.HeaderStyle.Render("│ ")
+			base = styles.HeaderStyle
+		}
+		keys := ""
+		if c.Keys != "" {
+			keys = "  " + c.Keys
+		}
+		title := truncate(c.Title, width-lipgloss.Width(keys))
+		line := lipgloss.StyleRunes(title, match.MatchedIndexes, base.Underline(true), base)
+		if pad := width - lipgloss.Width(title) - lipgloss.Width(keys); pad > 0 {
+			line += strings.Repeat(" ", pad)
+		}
+		sb.WriteString(cursor + line + styles.MutedStyle.Render(keys) + "\n")
+	}
+
+	help := styles.HelpStyle.Render(keymap.Join(
+		keymap.Hint("move", keymap.PaletteUp, keymap.PaletteDown),
+		keymap.Hint("run", keymap.Select),
+		keymap.Hint("close", keymap.Back),
+		fmt.Sprintf("%d/%d", len(m.matches), len(m.commands)),
+	))
+	return lipgloss.JoinVertical(lipgloss.Left,
+		styles.AppStyle.Render(strings.TrimRight(sb.String(), "\n")), help)
+}
+
+// truncate cuts s to width cells, marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
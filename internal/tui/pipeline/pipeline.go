@@ -0,0 +1,280 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/timefmt"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drone/drone-go/drone"
+)
+
+// node is a selectable row in the graph: a stage header or one of its steps
+type node struct {
+	stage *drone.Stage
+	step  *drone.Step // nil for stage headers
+	line  int         // line offset in the rendered content
+}
+
+type Model struct {
+	build      *drone.Build
+	levels     [][]*drone.Stage
+	nodes      []node
+	cursor     int
+	viewport   viewport.Model
+	width      int
+	height     int
+	pendingKey string
+}
+
+func New(build *drone.Build, width, height int) Model {
+	m := Model{
+		build:    build,
+		levels:   stageLevels(build.Stages),
+		viewport: viewport.New(width, height-2), // Account for separator + help line
+		width:    width,
+		height:   height,
+	}
+	m.refresh()
+	return m
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if kmsg, ok := msgin.(tea.KeyMsg); ok {
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(kmsg, prev, keymap.PipelineUp):
+			m.moveCursor(-1)
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.PipelineDown):
+			m.moveCursor(1)
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.NextTab):
+			// Jump to the next stage header
+			for i := m.cursor + 1; i < len(m.nodes); i++ {
+				if m.nodes[i].step == nil {
+					m.moveCursor(i - m.cursor)
+					break
+				}
+			}
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.PrevTab):
+			// Jump to the previous stage header
+			for i := m.cursor - 1; i >= 0; i-- {
+				if m.nodes[i].step == nil {
+					m.moveCursor(i - m.cursor)
+					break
+				}
+			}
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Select):
+			stageNum, stepNum, ok := m.SelectedStep()
+			if !ok {
+				return m, nil
+			}
+			return m, func() tea.Msg {
+				return msg.StepSelectedMsg{StageNum: stageNum, StepNum: stepNum}
+			}
+
+		case keymap.Matches(kmsg, prev, keymap.Top):
+			m.moveCursor(-m.cursor)
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Bottom):
+			m.moveCursor(len(m.nodes) - 1 - m.cursor)
+			return m, nil
+
+		case keymap.IsPrefix(kmsg, keymap.Graph):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = kmsg.String()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msgin)
+	return m, cmd
+}
+
+func (m Model) View() string {
+	if len(m.nodes) == 0 {
+		return styles.AppStyle.Render("No stages found in this build.")
+	}
+	help := styles.HelpStyle.Render(keymap.Join(
+		keymap.Hint("move", keymap.PipelineUp, keymap.PipelineDown),
+		keymap.Hint("stage", keymap.NextTab, keymap.PrevTab),
+		keymap.Hint("open logs", keymap.Select),
+		keymap.Help(keymap.OpenInBrowser),
+		keymap.Hint("back", keymap.Pipeline, keymap.Back),
+		keymap.Help(keymap.ShowHelp),
+	))
+	return lipgloss.JoinVertical(lipgloss.Left, m.viewport.View(), "", help)
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.viewport.Width = w
+	m.viewport.Height = h - 2 // Account for separator + help line
+	m.refresh()
+}
+
+// SelectedStep returns the stage and step numbers of the node under the
+// cursor. Stage headers resolve to their first step.
+func (m Model) SelectedStep() (stageNum, stepNum int, ok bool) {
+	if m.cursor < 0 || m.cursor >= len(m.nodes) {
+		return 0, 0, false
+	}
+	n := m.nodes[m.cursor]
+	if n.step != nil {
+		return n.stage.Number, n.step.Number, true
+	}
+	if len(n.stage.Steps) > 0 {
+		return n.stage.Number, n.stage.Steps[0].Number, true
+	}
+	return 0, 0, false
+}
+
+// SelectStep moves the cursor to the given step if it exists
+func (m *Model) SelectStep(stageNum, stepNum int) {
+	for i, n := range m.nodes {
+		if n.step != nil && n.stage.Number == stageNum && n.step.Number == stepNum {
+			m.moveCursor(i - m.cursor)
+			return
+		}
+	}
+}
+
+func (m *Model) moveCursor(delta int) {
+	if len(m.nodes) == 0 {
+		return
+	}
+	m.cursor = max(0, min(len(m.nodes)-1, m.cursor+delta))
+	m.refresh()
+
+	// Keep the cursor line inside the viewport
+	line := m.nodes[m.cursor].line
+	if line < m.viewport.YOffset {
+		m.viewport.SetYOffset(line)
+	} else if line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(line - m.viewport.Height + 1)
+	}
+}
+
+// refresh re-renders the graph into the viewport and records the line of
+// every selectable node
+func (m *Model) refresh() {
+	var lines []string
+	m.nodes = nil
+
+	for depth, level := range m.levels {
+		header := fmt.Sprintf("── %d ", depth+1)
+		if len(level) > 1 {
+			header += "(parallel) "
+		}
+		fill := max(0, m.width-lipgloss.Width(header)-2)
+		lines = append(lines, styles.BorderStyle.Render(header+strings.Repeat("─", fill)))
+
+		for _, stage := range level {
+			selected := len(m.nodes) == m.cursor
+			m.nodes = append(m.nodes, node{stage: stage, line: len(lines)})
+
+			name := stage.Name
+			if selected {
+				name = styles.HeaderStyle.Render("▶ " + name)
+			} else {
+				name = "  " + name
+			}
+			row := fmt.Sprintf("%s %s", styles.StatusIcon(stage.Status), name)
+			if len(stage.DependsOn) > 0 {
+				row += styles.HelpStyle.Render("  ← " + strings.Join(stage.DependsOn, ", "))
+			}
+			if d := timefmt.Elapsed(stage.Started, stage.Stopped); d != "" {
+				row += "  " + styles.MutedStyle.Render(d)
+			}
+			lines = append(lines, " "+row)
+
+			for i, step := range stage.Steps {
+				selected := len(m.nodes) == m.cursor
+				m.nodes = append(m.nodes, node{stage: stage, step: step, line: len(lines)})
+
+				branch := "├"
+				if i == len(stage.Steps)-1 {
+					branch = "└"
+				}
+				name := step.Name
+				if selected && styles.Current.Mono {
+					name = "▶ " + name
+				}
+				if selected {
+					name = styles.HeaderStyle.Render(name)
+				}
+				row := fmt.Sprintf("   %s %s %s", styles.BorderStyle.Render(branch), styles.StatusIcon(step.Status), name)
+				if len(step.DependsOn) > 0 {
+					row += styles.HelpStyle.Render("  ← " + strings.Join(step.DependsOn, ", "))
+				}
+				if d := timefmt.Elapsed(step.Started, step.Stopped); d != "" {
+					row += "  " + styles.MutedStyle.Render(d)
+				}
+				lines = append(lines, " "+row)
+			}
+		}
+		lines = append(lines, "")
+	}
+
+	m.viewport.SetContent(strings.TrimRight(strings.Join(lines, "\n"), "\n"))
+}
+
+// stageLevels groups stages by dependency depth. Stages in the same level
+// have all their dependencies satisfied by earlier levels and can run in
+// parallel.
+func stageLevels(stages []*drone.Stage) [][]*drone.Stage {
+	byName := make(map[string]*drone.Stage, len(stages))
+	for _, s := range stages {
+		byName[s.Name] = s
+	}
+
+	depths := make(map[*drone.Stage]int, len(stages))
+	visiting := make(map[*drone.Stage]bool)
+	var depth func(s *drone.Stage) int
+	depth = func(s *drone.Stage) int {
+		if d, ok := depths[s]; ok {
+			return d
+		}
+		if visiting[s] {
+			// Dependency cycle, treat as a root
+			return 0
+		}
+		visiting[s] = true
+		d := 0
+		for _, name := range s.DependsOn {
+			if dep, ok := byName[name]; ok {
+				d = max(d, depth(dep)+1)
+			}
+		}
+		visiting[s] = false
+		depths[s] = d
+		return d
+	}
+
+	var levels [][]*drone.Stage
+	for _, s := range stages {
+		d := depth(s)
+		for len(levels) <= d {
+			levels = append(levels, nil)
+		}
+		levels[d] = append(levels[d], s)
+	}
+	return levels
+}
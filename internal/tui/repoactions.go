@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (m Model) repoActionCmd(req msg.RepoActionMsg) tea.Cmd {
+	repo := m.selectedRepo
+	if repo == nil || repo.Slug != req.RepoSlug {
+		return nil
+	}
+	return func() tea.Msg {
+		done := msg.RepoActionDoneMsg{RepoSlug: repo.Slug, Action: req.Action}
+		switch req.Action {
+		case msg.RepoActivate:
+			done.Repo, done.Err = m.client.ActivateRepo(repo.Namespace, repo.Name)
+		case msg.RepoDeactivate:
+			if done.Err = m.client.DeactivateRepo(repo.Namespace, repo.Name); done.Err == nil {
+				r := *repo
+				r.Active = false
+				done.Repo = &r
+			}
+		case msg.RepoUpdate:
+			done.Repo, done.Err = m.client.UpdateRepo(repo.Namespace, repo.Name, req.Patch)
+		case msg.RepoRepair:
+			done.Err = m.client.RepairRepo(repo.Namespace, repo.Name)
+		case msg.RepoChown:
+			done.Repo, done.Err = m.client.ChownRepo(repo.Namespace, repo.Name)
+		}
+		return done
+	}
+}
+
+// handleRepoDone reports the outcome of a settings change and swaps the
+// updated repo into the repo list. If the user has moved on to another repo
+// meanwhile, the outcome is only flashed.
+func (m Model) handleRepoDone(done msg.RepoActionDoneMsg) (Model, tea.Cmd) {
+	if m.selectedRepo == nil || m.selectedRepo.Slug != done.RepoSlug {
+		if done.Err != nil {
+			return m.setFlash(fmt.Sprintf("%s: %v", done.RepoSlug, done.Err))
+		}
+		return m.setFlash(repoDoneText(done))
+	}
+
+	var listCmd tea.Cmd
+	if done.Repo != nil {
+		// Responses don't carry the latest build the repo list shows
+		done.Repo.Build = m.selectedRepo.Build
+		m.selectedRepo = done.Repo
+		listCmd = m.repoList.ReplaceRepo(done.Repo)
+	}
+	if m.settings.ActionDone(done) {
+		return m, listCmd
+	}
+	if done.Err != nil {
+		m, flashCmd := m.setFlash(fmt.Sprintf("%s: %v", done.RepoSlug, done.Err))
+		return m, tea.Batch(listCmd, flashCmd)
+	}
+	m, flashCmd := m.setFlash(repoDoneText(done))
+	return m, tea.Batch(listCmd, flashCmd)
+}
+
+// repoDoneText describes a settings change that went through
+func repoDoneText(done msg.RepoActionDoneMsg) string {
+	switch done.Action {
+	case msg.RepoActivate:
+		return "Activated " + done.RepoSlug
+	case msg.RepoDeactivate:
+		return "Deactivated " + done.RepoSlug
+	case msg.RepoUpdate:
+		return "Saved settings of " + done.RepoSlug
+	case msg.RepoRepair:
+		return "Repaired webhook of " + done.RepoSlug
+	case msg.RepoChown:
+		return "You now own " + done.RepoSlug
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package repos
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drone/drone-go/drone"
+)
+
+// headerItem starts a section of the list. Its empty filter value keeps it
+// out of filter results.
+type headerItem struct {
+	title     string
+	namespace string // set for collapsible namespace headers
+	collapsed bool
+	count     int
+	summary   string
+}
+
+func newHeader(title, namespace string, repos []*drone.Repo, collapsed bool) headerItem {
+	return headerItem{
+		title:     title,
+		namespace: namespace,
+		collapsed: collapsed,
+		count:     len(repos),
+		summary:   statusSummary(repos),
+	}
+}
+
+func (i headerItem) Title() string {
+	if i.namespace == "" {
+		return i.title
+	}
+	if i.collapsed {
+		return "▸ " + i.title
+	}
+	return "▾ " + i.title
+}
+
+func (i headerItem) FilterValue() string { return "" }
+
+func (i headerItem) Description() string {
+	desc := fmt.Sprintf("%d repositories", i.count)
+	if i.count == 1 {
+		desc = "1 repository"
+	}
+	if i.summary != "" {
+		desc += " · " + i.summary
+	}
+	return desc
+}
+
+// groupedItems returns repos under one header per namespace, in namespace
+// order. Collapsed namespaces keep their repos hidden unless a filter is
+// active.
+func (m Model) groupedItems(repos []*drone.Repo) []list.Item {
+	byNamespace := make(map[string][]*drone.Repo)
+	var namespaces []string
+	for _, r := range repos {
+		if _, ok := byNamespace[r.Namespace]; !ok {
+			namespaces = append(namespaces, r.Namespace)
+		}
+		byNamespace[r.Namespace] = append(byNamespace[r.Namespace], r)
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		return strings.ToLower(namespaces[i]) < strings.ToLower(namespaces[j])
+	})
+
+	filtering := m.list.FilterState() != list.Unfiltered
+	var items []list.Item
+	for _, ns := range namespaces {
+		group := byNamespace[ns]
+		collapsed := m.collapsed[ns]
+		items = append(items, newHeader(ns, ns, group, collapsed))
+		if collapsed && !filtering {
+			continue
+		}
+		for _, r := range group {
+			items = append(items, repoItem{repo: r, highlightUntil: m.highlightUntil[r.Slug]})
+		}
+	}
+	return items
+}
+
+// toggleCollapsed opens or closes a namespace, keeping the cursor on its
+// header
+func (m *Model) toggleCollapsed(namespace string) tea.Cmd {
+	m.collapsed[namespace] = !m.collapsed[namespace]
+	cmd := m.list.SetItems(m.items())
+	for i, item := range m.list.Items() {
+		if h, ok := item.(headerItem); ok && h.namespace == namespace {
+			m.list.Select(i)
+			break
+		}
+	}
+	return cmd
+}
+
+// statusSummary counts the latest build statuses of repos, e.g.
+// "3 failing, 1 running"
+func statusSummary(repos []*drone.Repo) string {
+	var failing, running, pending, passing int
+	for _, r := range repos {
+		if !r.Active || r.Build.Number == 0 {
+			continue
+		}
+		switch r.Build.Status {
+		case "failure", "error", "killed":
+			failing++
+		case "running":
+			running++
+		case "pending":
+			pending++
+		case "success":
+			passing++
+		}
+	}
+	var parts []string
+	add := func(n int, label string) {
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, label))
+		}
+	}
+	add(failing, "failing")
+	add(running, "running")
+	add(pending, "pending")
+	add(passing, "passing")
+	return strings.Join(parts, ", ")
+}
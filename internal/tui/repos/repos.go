@@ -6,18 +6,34 @@ import (
 	"strings"
 	"time"
 
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
 	"github.com/arch-err/drone-tui/internal/tui/msg"
 	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/timefmt"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/drone/drone-go/drone"
 )
 
+// highlightDuration is how long repos changed by an auto-refresh stay marked
+const highlightDuration = 3 * time.Second
+
 type repoItem struct {
-	repo *drone.Repo
+	repo           *drone.Repo
+	favorite       bool
+	highlightUntil time.Time
 }
 
-func (i repoItem) Title() string       { return i.repo.Slug }
+func (i repoItem) Title() string {
+	title := i.repo.Slug
+	if i.favorite {
+		title = favoriteMarker() + " " + title
+	}
+	if time.Now().Before(i.highlightUntil) {
+		return title + " " + styles.ChangedMarker
+	}
+	return title
+}
 func (i repoItem) FilterValue() string { return i.repo.Slug }
 func (i repoItem) Description() string {
 	if !i.repo.Active {
@@ -31,49 +47,128 @@ func (i repoItem) Description() string {
 		fmt.Sprintf("%s #%d", styles.StatusIcon(b.Status), b.Number),
 	}
 	if b.Finished > 0 {
-		parts = append(parts, timeAgo(b.Finished))
+		parts = append(parts, timefmt.Ago(b.Finished))
 	}
 	return strings.Join(parts, " · ")
 }
 
+func favoriteMarker() string {
+	return styles.HighlightStyle.Render("★")
+}
+
 type Model struct {
-	list            list.Model
-	allRepos        []*drone.Repo
-	showInactive    bool
-	lastEscapeAt    time.Time
-	showEscapeHint  bool
-	width           int
-	height          int
-	pendingGCount   int
+	list          list.Model
+	allRepos      []*drone.Repo
+	showInactive  bool
+	favorites     map[string]bool
+	favoritesOnly bool
+	// Group repos under namespace headers; collapsed namespaces only show
+	// their header while no filter is active
+	grouped        bool
+	collapsed      map[string]bool
+	lastEscapeAt   time.Time
+	showEscapeHint bool
+	width          int
+	height         int
+	pendingKey     string
+
+	// Repos changed by the last auto-refresh, keyed by slug
+	highlightUntil map[string]time.Time
+	// Slug to reselect once an in-flight filter completes after a merge
+	restoreSlug string
 }
 
-func New(repos []*drone.Repo, width, height int) Model {
+// New creates the repo list with the given slugs starred
+func New(repos []*drone.Repo, favorites []string, width, height int) Model {
 	m := Model{
 		allRepos:     repos,
 		showInactive: false,
+		favorites:    make(map[string]bool, len(favorites)),
+		collapsed:    make(map[string]bool),
 		width:        width,
 		height:       height,
 	}
+	for _, slug := range favorites {
+		m.favorites[slug] = true
+	}
 	m.rebuildList()
-	// Start in filter mode by simulating "/" key press
-	keyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}}
-	m.list, _ = m.list.Update(keyMsg)
+	// Start in filter mode by simulating the filter key press
+	for _, k := range keymap.Press(keymap.ListFilter) {
+		m.list, _ = m.list.Update(k)
+	}
 	return m
 }
 
 func (m *Model) rebuildList() {
-	var filtered []*drone.Repo
+	m.list = list.New(m.items(), styles.NewDelegate(), m.width, m.height)
+	styles.ApplyList(&m.list)
+	m.list.Title = "Repositories"
+	switch {
+	case m.favoritesOnly:
+		m.list.Title = "Repositories (favorites)"
+	case m.showInactive:
+		m.list.Title = "Repositories (showing all)"
+	}
+	if m.grouped {
+		m.list.Title += " by org"
+	}
+	m.list.KeyMap = keymap.ListKeyMap()
+	m.list.SetShowStatusBar(true)
+	m.list.SetFilteringEnabled(true)
+	// Start on the first repo rather than a section header
+	if items := m.list.Items(); len(items) > 1 {
+		if _, ok := items[0].(headerItem); ok {
+			m.list.Select(1)
+		}
+	}
+}
+
+// items returns the visible repos as sorted list items, with starred repos
+// pinned in their own section at the top
+func (m Model) items() []list.Item {
+	var favorites, others []*drone.Repo
 	for _, r := range m.allRepos {
+		if m.favorites[r.Slug] {
+			// Starred repos are shown even when inactive
+			favorites = append(favorites, r)
+			continue
+		}
+		if m.favoritesOnly {
+			continue
+		}
 		if !m.showInactive {
 			// Hide inactive repos and repos with no builds
 			if !r.Active || r.Build.Number == 0 {
 				continue
 			}
 		}
-		filtered = append(filtered, r)
+		others = append(others, r)
+	}
+	sortByRecency(favorites)
+	sortByRecency(others)
+
+	var items []list.Item
+	if len(favorites) > 0 && !m.favoritesOnly {
+		items = append(items, newHeader(favoriteMarker()+" Favorites", "", favorites, false))
 	}
+	for _, r := range favorites {
+		items = append(items, repoItem{repo: r, favorite: true, highlightUntil: m.highlightUntil[r.Slug]})
+	}
+	if m.grouped {
+		return append(items, m.groupedItems(others)...)
+	}
+	if len(favorites) > 0 && len(others) > 0 {
+		items = append(items, newHeader("Repositories", "", others, false))
+	}
+	for _, r := range others {
+		items = append(items, repoItem{repo: r, highlightUntil: m.highlightUntil[r.Slug]})
+	}
+	return items
+}
 
-	// Sort by build recency (most recent first, no builds at end)
+// sortByRecency sorts repos by build recency (most recent first, no builds
+// at end)
+func sortByRecency(filtered []*drone.Repo) {
 	sort.Slice(filtered, func(i, j int) bool {
 		iTime := filtered[i].Build.Finished
 		if iTime == 0 {
@@ -92,20 +187,111 @@ func (m *Model) rebuildList() {
 		}
 		return iTime > jTime
 	})
+}
 
-	items := make([]list.Item, len(filtered))
-	for i, r := range filtered {
-		items[i] = repoItem{repo: r}
+// Merge swaps in freshly loaded repos without rebuilding the list, so the
+// cursor, filter text and scroll position survive. Repos whose latest build
+// changed are highlighted briefly.
+func (m *Model) Merge(repos []*drone.Repo) tea.Cmd {
+	previous := make(map[string]drone.Build, len(m.allRepos))
+	for _, r := range m.allRepos {
+		previous[r.Slug] = r.Build
 	}
 
-	m.list = list.New(items, list.NewDefaultDelegate(), m.width, m.height)
-	m.list.Title = "Repositories"
-	if m.showInactive {
-		m.list.Title = "Repositories (showing all)"
+	until := time.Now().Add(highlightDuration)
+	m.highlightUntil = make(map[string]time.Time)
+	changed := false
+	for _, r := range repos {
+		old, ok := previous[r.Slug]
+		if !ok || old.Number != r.Build.Number || old.Status != r.Build.Status {
+			m.highlightUntil[r.Slug] = until
+			changed = true
+		}
 	}
-	m.list.DisableQuitKeybindings()
-	m.list.SetShowStatusBar(true)
-	m.list.SetFilteringEnabled(true)
+
+	if selected := m.SelectedRepo(); selected != nil {
+		m.restoreSlug = selected.Slug
+	}
+	m.allRepos = repos
+	cmds := []tea.Cmd{m.list.SetItems(m.items())}
+	m.restoreSelection()
+
+	if changed {
+		cmds = append(cmds, tea.Tick(highlightDuration, func(t time.Time) tea.Msg {
+			return msg.ClearHighlightMsg{}
+		}))
+	}
+	return tea.Batch(cmds...)
+}
+
+// ReplaceRepo swaps in a repo changed from the settings view
+func (m *Model) ReplaceRepo(repo *drone.Repo) tea.Cmd {
+	repos := make([]*drone.Repo, len(m.allRepos))
+	for i, r := range m.allRepos {
+		if r.Slug == repo.Slug {
+			r = repo
+		}
+		repos[i] = r
+	}
+	if selected := m.SelectedRepo(); selected != nil {
+		m.restoreSlug = selected.Slug
+	}
+	m.allRepos = repos
+	cmd := m.list.SetItems(m.items())
+	m.restoreSelection()
+	return cmd
+}
+
+// toggleFavorite stars or unstars slug, keeping the cursor on it, and tells
+// the app so the favorites are saved
+func (m *Model) toggleFavorite(slug string) tea.Cmd {
+	if m.favorites[slug] {
+		delete(m.favorites, slug)
+	} else {
+		m.favorites[slug] = true
+	}
+	m.restoreSlug = slug
+	cmd := m.list.SetItems(m.items())
+	m.restoreSelection()
+
+	favorites := m.Favorites()
+	return tea.Batch(cmd, func() tea.Msg {
+		return msg.FavoritesChangedMsg{Favorites: favorites}
+	})
+}
+
+// Favorites returns the starred slugs in sorted order
+func (m Model) Favorites() []string {
+	favorites := make([]string, 0, len(m.favorites))
+	for slug := range m.favorites {
+		favorites = append(favorites, slug)
+	}
+	sort.Strings(favorites)
+	return favorites
+}
+
+// restoreSelection moves the cursor back to restoreSlug if it is visible
+func (m *Model) restoreSelection() {
+	if m.restoreSlug == "" {
+		return
+	}
+	for i, item := range m.list.VisibleItems() {
+		if r, ok := item.(repoItem); ok && r.repo.Slug == m.restoreSlug {
+			m.list.Select(i)
+			m.restoreSlug = ""
+			return
+		}
+	}
+}
+
+// HasActive reports whether any listed repo has a running or pending build
+func (m Model) HasActive() bool {
+	for _, r := range m.allRepos {
+		if r.Build.Status == "running" || r.Build.Status == "pending" {
+			return true
+		}
+	}
+	return false
 }
 
 func (m Model) Init() tea.Cmd {
@@ -119,80 +305,119 @@ func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
-		switch msgin.String() {
-		case "enter":
-			if !m.IsFiltering() {
-				if item, ok := m.list.SelectedItem().(repoItem); ok {
-					return m, func() tea.Msg {
-						return msg.RepoSelectedMsg{Repo: item.repo}
-					}
+		// The user moved on, don't yank the cursor back after a merge
+		m.restoreSlug = ""
+
+		if m.IsFiltering() {
+			break
+		}
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(msgin, prev, keymap.Select):
+			switch item := m.list.SelectedItem().(type) {
+			case repoItem:
+				return m, func() tea.Msg {
+					return msg.RepoSelectedMsg{Repo: item.repo}
+				}
+			case headerItem:
+				if item.namespace != "" {
+					return m, m.toggleCollapsed(item.namespace)
 				}
+				return m, nil
 			}
 
-		case "a":
+		case keymap.Matches(msgin, prev, keymap.ToggleInactive):
 			// Toggle showing inactive repos
-			if !m.IsFiltering() {
-				m.showInactive = !m.showInactive
-				m.rebuildList()
-				return m, nil
+			m.showInactive = !m.showInactive
+			m.rebuildList()
+			return m, nil
+
+		case keymap.Matches(msgin, prev, keymap.FavoritesOnly):
+			// Toggle showing only starred repos
+			m.favoritesOnly = !m.favoritesOnly
+			m.rebuildList()
+			return m, nil
+
+		case keymap.Matches(msgin, prev, keymap.GroupByOrg):
+			// Toggle grouping by namespace
+			m.grouped = !m.grouped
+			m.rebuildList()
+			return m, nil
+
+		case keymap.Matches(msgin, prev, keymap.ToggleFavorite):
+			if item, ok := m.list.SelectedItem().(repoItem); ok {
+				return m, m.toggleFavorite(item.repo.Slug)
 			}
+			return m, nil
 
-		case "esc":
+		case keymap.Matches(msgin, prev, keymap.Back):
 			// Double-escape to quit
-			if !m.IsFiltering() {
-				now := time.Now()
-				if now.Sub(m.lastEscapeAt) < 500*time.Millisecond {
-					m.showEscapeHint = false
-					return m, tea.Quit
-				}
-				m.lastEscapeAt = now
-				m.showEscapeHint = true
-				// Set timer to clear hint after 2 seconds
-				return m, tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
-					return msg.ClearEscapeHintMsg{}
-				})
+			now := time.Now()
+			if now.Sub(m.lastEscapeAt) < 500*time.Millisecond {
+				m.showEscapeHint = false
+				return m, tea.Quit
 			}
+			m.lastEscapeAt = now
+			m.showEscapeHint = true
+			// Set timer to clear hint after 2 seconds
+			return m, tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
+				return msg.ClearEscapeHintMsg{}
+			})
 
-		case "g":
-			// Vim binding: gg to go to top
-			if !m.IsFiltering() {
-				m.pendingGCount++
-				if m.pendingGCount == 2 {
-					m.list.Select(0)
-					m.pendingGCount = 0
-				}
-				return m, nil
-			}
+		case keymap.Matches(msgin, prev, keymap.Top):
+			m.list.Select(0)
+			return m, nil
 
-		case "G":
-			// Vim binding: G to go to bottom
-			if !m.IsFiltering() {
-				m.list.Select(len(m.list.Items()) - 1)
-				m.pendingGCount = 0
-			}
+		case keymap.Matches(msgin, prev, keymap.Bottom):
+			m.list.Select(len(m.list.Items()) - 1)
 			return m, nil
 
-		default:
-			// Reset pending g count on any other key
-			m.pendingGCount = 0
+		case keymap.IsPrefix(msgin, keymap.Repos):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = msgin.String()
+			return m, nil
 		}
 	}
 
+	wasFiltered := m.list.FilterState() != list.Unfiltered
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msgin)
+	if _, ok := msgin.(list.FilterMatchesMsg); ok {
+		m.restoreSelection()
+	}
+	if filtered := m.list.FilterState() != list.Unfiltered; m.grouped && filtered != wasFiltered {
+		// Collapsed namespaces open up while filtering so their repos can
+		// be found, and close again once the filter is cleared
+		cmd = tea.Batch(cmd, m.list.SetItems(m.items()))
+	}
 	return m, cmd
 }
 
 func (m Model) View() string {
 	help := ""
 	if !m.IsFiltering() {
+		back := keymap.Label(keymap.Back)
+		toggle := keymap.Hint("show all", keymap.ToggleInactive)
+		quit := ""
+		if back != "" {
+			quit = back + " " + back + ": quit"
+		}
+		if m.showInactive {
+			toggle = keymap.Hint("hide inactive", keymap.ToggleInactive)
+			quit = ""
+		}
 		if m.showEscapeHint {
 			// Show escape hint when user pressed escape once
-			help = styles.HelpStyle.Render("Press escape again to exit")
-		} else if m.showInactive {
-			help = styles.HelpStyle.Render("a: hide inactive · r: refresh · gx: open in browser")
+			help = styles.HelpStyle.Render("Press " + back + " again to exit")
 		} else {
-			help = styles.HelpStyle.Render("a: show all · r: refresh · gx: open in browser · esc esc: quit")
+			help = styles.HelpStyle.Render(keymap.Join(
+				toggle,
+				keymap.Help(keymap.GroupByOrg, keymap.ToggleFavorite, keymap.FavoritesOnly, keymap.Settings,
+					keymap.Dashboard, keymap.Sync, keymap.ShowHelp),
+				quit,
+			))
 		}
 	}
 	if help != "" {
@@ -211,37 +436,14 @@ func (m *Model) SetSize(w, h int) {
 	m.list.SetSize(w, h)
 }
 
+// Repos returns every loaded repo, including hidden ones
+func (m Model) Repos() []*drone.Repo {
+	return m.allRepos
+}
+
 func (m Model) SelectedRepo() *drone.Repo {
 	if item, ok := m.list.SelectedItem().(repoItem); ok {
 		return item.repo
 	}
 	return nil
 }
-
-func timeAgo(unix int64) string {
-	t := time.Unix(unix, 0)
-	d := time.Since(t)
-
-	switch {
-	case d < time.Minute:
-		return "just now"
-	case d < time.Hour:
-		m := int(d.Minutes())
-		if m == 1 {
-			return "1 minute ago"
-		}
-		return fmt.Sprintf("%d minutes ago", m)
-	case d < 24*time.Hour:
-		h := int(d.Hours())
-		if h == 1 {
-			return "1 hour ago"
-		}
-		return fmt.Sprintf("%d hours ago", h)
-	default:
-		days := int(d.Hours() / 24)
-		if days == 1 {
-			return "1 day ago"
-		}
-		return fmt.Sprintf("%d days ago", days)
-	}
-}
@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// secretScope returns the repo name to pass to the client, empty for the
+// organization secrets of the selected repo's namespace
+func (m Model) secretScope(org bool) (namespace, name string) {
+	if org {
+		return m.selectedRepo.Namespace, ""
+	}
+	return m.selectedRepo.Namespace, m.selectedRepo.Name
+}
+
+func (m Model) loadSecretsCmd(org bool) tea.Cmd {
+	slug := m.selectedRepo.Slug
+	namespace, name := m.secretScope(org)
+	return func() tea.Msg {
+		secretList, err := m.client.ListSecrets(namespace, name)
+		return msg.SecretsLoadedMsg{RepoSlug: slug, Org: org, Secrets: secretList, Err: err}
+	}
+}
+
+func (m Model) secretActionCmd(req msg.SecretActionMsg) tea.Cmd {
+	if m.selectedRepo == nil {
+		return nil
+	}
+	slug := m.selectedRepo.Slug
+	namespace, name := m.secretScope(req.Org)
+	return func() tea.Msg {
+		done := msg.SecretActionDoneMsg{RepoSlug: slug, Action: req.Action, Name: req.Secret.Name}
+		switch req.Action {
+		case msg.SecretCreate:
+			done.Err = m.client.CreateSecret(namespace, name, req.Secret)
+		case msg.SecretUpdate:
+			done.Err = m.client.UpdateSecret(namespace, name, req.Secret.Name, req.Patch)
+		case msg.SecretDelete:
+			done.Err = m.client.DeleteSecret(namespace, name, req.Secret.Name)
+		}
+		return done
+	}
+}
+
+// handleSecretDone reports the outcome of a secret change and reloads the
+// list. Only the secret's name is ever shown. If the user has moved on to
+// another repo meanwhile, the outcome is only flashed.
+func (m Model) handleSecretDone(done msg.SecretActionDoneMsg) (Model, tea.Cmd) {
+	if m.selectedRepo == nil || m.selectedRepo.Slug != done.RepoSlug {
+		if done.Err != nil {
+			return m.setFlash(fmt.Sprintf("%s: secret %s: %v", done.RepoSlug, done.Name, done.Err))
+		}
+		return m.setFlash(fmt.Sprintf("%s: %s", done.RepoSlug, secretDoneText(done)))
+	}
+	if m.secrets.ActionDone(done) {
+		return m, nil
+	}
+	if done.Err != nil {
+		return m.setFlash(fmt.Sprintf("Secret %s: %v", done.Name, done.Err))
+	}
+
+	m, flashCmd := m.setFlash(secretDoneText(done))
+	return m, tea.Batch(flashCmd, m.loadSecretsCmd(m.secrets.Org()))
+}
+
+// secretDoneText describes a secret change that went through
+func secretDoneText(done msg.SecretActionDoneMsg) string {
+	switch done.Action {
+	case msg.SecretCreate:
+		return "Created secret " + done.Name
+	case msg.SecretUpdate:
+		return "Updated secret " + done.Name
+	case msg.SecretDelete:
+		return "Deleted secret " + done.Name
+	}
+	return ""
+}
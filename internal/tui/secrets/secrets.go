@@ -0,0 +1,312 @@
+package secrets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/client"
+	"github.com/arch-err/drone-tui/internal/tui/form"
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/textfmt"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drone/drone-go/drone"
+)
+
+// secretItem holds only what the server returns about a secret: its name
+// and flags. Values are never sent back.
+type secretItem struct {
+	secret *drone.Secret
+}
+
+func (i secretItem) Title() string {
+	return "🔒 " + i.secret.Name
+}
+
+func (i secretItem) FilterValue() string {
+	return i.secret.Name
+}
+
+func (i secretItem) Description() string {
+	return fmt.Sprintf("pull_request: %s | pull_request_push: %s",
+		textfmt.YesNo(i.secret.PullRequest), textfmt.YesNo(i.secret.PullRequestPush))
+}
+
+type Model struct {
+	list       list.Model
+	loaded     bool
+	err        error
+	org        bool
+	namespace  string
+	width      int
+	pendingKey string
+
+	// Create/edit form; editName is the secret being edited, empty when
+	// creating a new one
+	form     form.Model
+	editing  bool
+	editName string
+
+	// Secret awaiting delete confirmation
+	confirmDelete string
+}
+
+// New creates the secrets view for a repo in namespace, showing the repo's
+// secrets first
+func New(namespace string, width, height int) Model {
+	l := list.New(nil, styles.NewDelegate(), width, height)
+	styles.ApplyList(&l)
+	l.SetShowTitle(false) // Title shown in external statusbar instead
+	l.KeyMap = keymap.ListKeyMap()
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.SetStatusBarItemName("secret", "secrets")
+	m := Model{list: l, namespace: namespace, width: width}
+	m.SetSize(width, height)
+	return m
+}
+
+// SetSecrets fills the list, keeping the cursor on the same secret where
+// possible
+func (m *Model) SetSecrets(secretList []*drone.Secret, err error) tea.Cmd {
+	m.loaded = true
+	m.err = err
+	if err != nil {
+		return nil
+	}
+
+	var selected string
+	if item, ok := m.list.SelectedItem().(secretItem); ok {
+		selected = item.secret.Name
+	}
+
+	sorted := make([]*drone.Secret, len(secretList))
+	copy(sorted, secretList)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	items := make([]list.Item, len(sorted))
+	cursor := 0
+	for i, s := range sorted {
+		items[i] = secretItem{secret: s}
+		if s.Name == selected {
+			cursor = i
+		}
+	}
+	cmd := m.list.SetItems(items)
+	m.list.Select(cursor)
+	return cmd
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if m.editing {
+		return m.updateForm(msgin)
+	}
+
+	kmsg, ok := msgin.(tea.KeyMsg)
+	if ok && m.confirmDelete != "" {
+		name := m.confirmDelete
+		m.confirmDelete = ""
+		if keymap.Matches(kmsg, "", keymap.Confirm) {
+			return m, m.action(msg.SecretDelete, &drone.Secret{Name: name}, nil)
+		}
+		return m, nil
+	}
+
+	if ok && !m.IsFiltering() {
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(kmsg, prev, keymap.SecretScope):
+			// Switch between repo and organization secrets
+			m.org = !m.org
+			m.loaded = false
+			m.list.ResetFilter()
+			org := m.org
+			return m, tea.Batch(m.list.SetItems(nil), func() tea.Msg {
+				return msg.SecretScopeChangedMsg{Org: org}
+			})
+
+		case keymap.Matches(kmsg, prev, keymap.New):
+			m.editing = true
+			m.editName = ""
+			m.form = form.New("New "+m.scopeLabel(),
+				form.Text("name", "Name", "", "docker_password"),
+				form.Masked("data", "Value", ""),
+				form.Toggle("pull_request", "Allow pull requests", false),
+				form.Toggle("pull_request_push", "Allow pull request push", false),
+			)
+			m.form.SetWidth(m.width)
+			return m, m.form.Init()
+
+		case keymap.Matches(kmsg, prev, keymap.Edit, keymap.Select):
+			if item, ok := m.list.SelectedItem().(secretItem); ok {
+				s := item.secret
+				m.editing = true
+				m.editName = s.Name
+				m.form = form.New("Edit "+m.scopeLabel()+" "+s.Name,
+					form.Text("name", "Name", s.Name, "").ReadOnly(),
+					form.Masked("data", "New value", "leave empty to keep the current value"),
+					form.Toggle("pull_request", "Allow pull requests", s.PullRequest),
+					form.Toggle("pull_request_push", "Allow pull request push", s.PullRequestPush),
+				)
+				m.form.SetWidth(m.width)
+				return m, m.form.Init()
+			}
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Delete):
+			if item, ok := m.list.SelectedItem().(secretItem); ok {
+				m.confirmDelete = item.secret.Name
+			}
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Top):
+			m.list.Select(0)
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Bottom):
+			m.list.Select(len(m.list.Items()) - 1)
+			return m, nil
+
+		case keymap.IsPrefix(kmsg, keymap.Secrets):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = kmsg.String()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msgin)
+	return m, cmd
+}
+
+func (m Model) updateForm(msgin tea.Msg) (Model, tea.Cmd) {
+	if m.form.Status() == form.Submitted {
+		// Waiting for the save to finish
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.form, cmd = m.form.Update(msgin)
+
+	switch m.form.Status() {
+	case form.Canceled:
+		m.editing = false
+		m.form = form.Model{}
+		return m, nil
+
+	case form.Submitted:
+		data := m.form.Value("data")
+		pullRequest := m.form.Checked("pull_request")
+		pullRequestPush := m.form.Checked("pull_request_push")
+
+		if m.editName != "" {
+			patch := &client.SecretPatch{PullRequest: &pullRequest, PullRequestPush: &pullRequestPush}
+			if data != "" {
+				patch.Data = &data
+			}
+			return m, m.action(msg.SecretUpdate, &drone.Secret{Name: m.editName}, patch)
+		}
+
+		s := &drone.Secret{
+			Name:            m.form.Value("name"),
+			Data:            data,
+			PullRequest:     pullRequest,
+			PullRequestPush: pullRequestPush,
+		}
+		switch {
+		case s.Name == "":
+			m.form.Reopen("name is required")
+		case strings.ContainsAny(s.Name, " /"):
+			m.form.Reopen("name can't contain spaces or slashes")
+		case s.Data == "":
+			m.form.Reopen("value is required")
+		default:
+			return m, m.action(msg.SecretCreate, s, nil)
+		}
+		return m, nil
+	}
+	return m, cmd
+}
+
+// ActionDone closes the form after a successful save, or reopens it with
+// the error. It reports whether the error was shown in the form.
+func (m *Model) ActionDone(done msg.SecretActionDoneMsg) bool {
+	if !m.editing || done.Action == msg.SecretDelete {
+		return false
+	}
+	if done.Err != nil {
+		m.form.Reopen(done.Err.Error())
+		return true
+	}
+	// Drop the form so the value doesn't linger in memory
+	m.editing = false
+	m.form = form.Model{}
+	return false
+}
+
+func (m Model) action(a msg.SecretAction, s *drone.Secret, patch *client.SecretPatch) tea.Cmd {
+	org := m.org
+	return func() tea.Msg {
+		return msg.SecretActionMsg{Action: a, Org: org, Secret: s, Patch: patch}
+	}
+}
+
+func (m Model) View() string {
+	if m.editing {
+		return m.form.View()
+	}
+	if !m.loaded {
+		return lipgloss.JoinVertical(lipgloss.Left, m.header(), styles.AppStyle.Render("Loading secrets..."))
+	}
+	if m.err != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, m.header(),
+			styles.AppStyle.Render(fmt.Sprintf("Error loading secrets: %v", m.err)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, m.header(), m.list.View())
+}
+
+func (m Model) header() string {
+	if m.confirmDelete != "" {
+		return styles.StatusFailure.Render(fmt.Sprintf("Delete %s %s? (%s/N)", m.scopeLabel(), m.confirmDelete, keymap.Label(keymap.Confirm)))
+	}
+	title := "Repository secrets"
+	other := "org"
+	if m.org {
+		title = "Organization secrets: " + m.namespace
+		other = "repo"
+	}
+	return styles.HeaderStyle.Render(title) +
+		styles.HelpStyle.Render("  "+keymap.Join(
+			keymap.Help(keymap.New, keymap.Edit, keymap.Delete),
+			keymap.Hint(other+" secrets", keymap.SecretScope),
+		))
+}
+
+func (m Model) scopeLabel() string {
+	if m.org {
+		return "organization secret"
+	}
+	return "secret"
+}
+
+// Org reports whether organization secrets are shown
+func (m Model) Org() bool {
+	return m.org
+}
+
+// IsFiltering reports whether keys are going to the filter, the form or
+// the delete confirmation
+func (m Model) IsFiltering() bool {
+	return m.editing || m.confirmDelete != "" || m.list.FilterState() == list.Filtering
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.list.SetSize(w, h-1) // Account for header
+	m.form.SetWidth(w)
+}
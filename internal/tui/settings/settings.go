@@ -0,0 +1,339 @@
+package settings
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arch-err/drone-tui/internal/tui/form"
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/textfmt"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drone/drone-go/drone"
+)
+
+var visibilities = []string{"public", "private", "internal"}
+
+// change is one edited setting shown in the confirmation diff
+type change struct {
+	label string
+	from  string
+	to    string
+}
+
+type Model struct {
+	repo     *drone.Repo
+	viewport viewport.Model
+	width    int
+
+	form    form.Model
+	editing bool
+
+	// Pending update awaiting confirmation of its diff
+	patch   *drone.RepoPatch
+	changes []change
+
+	// Action awaiting y/N confirmation, and the action in flight
+	confirm *msg.RepoAction
+	busy    string
+}
+
+func New(repo *drone.Repo, width, height int) Model {
+	vp := viewport.New(width, height)
+	m := Model{repo: repo, viewport: vp, width: width}
+	m.refresh()
+	return m
+}
+
+// SetRepo shows repo as returned by the server after a change
+func (m *Model) SetRepo(repo *drone.Repo) {
+	m.repo = repo
+	m.busy = ""
+	m.refresh()
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if m.editing {
+		return m.updateForm(msgin)
+	}
+
+	kmsg, ok := msgin.(tea.KeyMsg)
+	if !ok || m.busy != "" {
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msgin)
+		return m, cmd
+	}
+
+	if m.patch != nil {
+		// Diff confirmation: y sends, anything else goes back to the form
+		patch := m.patch
+		m.patch = nil
+		if keymap.Matches(kmsg, "", keymap.Confirm) {
+			m.busy = "Saving settings..."
+			m.refresh()
+			return m, m.action(msg.RepoUpdate, patch)
+		}
+		m.editing = true
+		m.form.Reopen("")
+		return m, m.form.Init()
+	}
+
+	if m.confirm != nil {
+		a := *m.confirm
+		m.confirm = nil
+		if keymap.Matches(kmsg, "", keymap.Confirm) {
+			return m.start(a)
+		}
+		m.refresh()
+		return m, nil
+	}
+
+	switch {
+	case keymap.Matches(kmsg, "", keymap.Edit, keymap.Select):
+		m.openForm()
+		return m, m.form.Init()
+
+	case keymap.Matches(kmsg, "", keymap.ToggleActive):
+		if m.repo.Active {
+			a := msg.RepoDeactivate
+			m.confirm = &a
+			m.refresh()
+			return m, nil
+		}
+		return m.start(msg.RepoActivate)
+
+	case keymap.Matches(kmsg, "", keymap.Repair):
+		return m.start(msg.RepoRepair)
+
+	case keymap.Matches(kmsg, "", keymap.Chown):
+		a := msg.RepoChown
+		m.confirm = &a
+		m.refresh()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msgin)
+	return m, cmd
+}
+
+// start sends a, showing progress until ActionDone
+func (m Model) start(a msg.RepoAction) (Model, tea.Cmd) {
+	switch a {
+	case msg.RepoActivate:
+		m.busy = "Activating..."
+	case msg.RepoDeactivate:
+		m.busy = "Deactivating..."
+	case msg.RepoRepair:
+		m.busy = "Repairing webhook..."
+	case msg.RepoChown:
+		m.busy = "Taking ownership..."
+	}
+	m.refresh()
+	return m, m.action(a, nil)
+}
+
+func (m *Model) openForm() {
+	r := m.repo
+	m.editing = true
+	m.form = form.New("Edit settings: "+r.Slug,
+		form.Text("config", "Config path", r.Config, ".drone.yml"),
+		form.Text("timeout", "Timeout (min)", strconv.FormatInt(r.Timeout, 10), "60"),
+		form.Text("visibility", "Visibility", r.Visibility, strings.Join(visibilities, ", ")),
+		form.Toggle("protected", "Protected", r.Protected),
+		form.Toggle("trusted", "Trusted", r.Trusted),
+		form.Toggle("cancel_pulls", "Auto-cancel pull requests", r.CancelPulls),
+		form.Toggle("cancel_push", "Auto-cancel pushes", r.CancelPush),
+	)
+	m.form.SetWidth(m.width)
+}
+
+func (m Model) updateForm(msgin tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.form, cmd = m.form.Update(msgin)
+
+	switch m.form.Status() {
+	case form.Canceled:
+		m.editing = false
+		m.refresh()
+		return m, nil
+
+	case form.Submitted:
+		patch, changes, err := m.diff()
+		if err != nil {
+			m.form.Reopen(err.Error())
+			return m, nil
+		}
+		if len(changes) == 0 {
+			m.form.Reopen("nothing changed")
+			return m, nil
+		}
+		m.editing = false
+		m.patch = patch
+		m.changes = changes
+		m.refresh()
+		return m, nil
+	}
+	return m, cmd
+}
+
+// diff validates the form and returns a patch holding only the changed
+// settings
+func (m Model) diff() (*drone.RepoPatch, []change, error) {
+	r := m.repo
+	patch := new(drone.RepoPatch)
+	var changes []change
+
+	if v := m.form.Value("config"); v != r.Config {
+		if v == "" {
+			return nil, nil, fmt.Errorf("config path is required")
+		}
+		patch.Config = &v
+		changes = append(changes, change{"Config path", r.Config, v})
+	}
+
+	timeout, err := strconv.ParseInt(m.form.Value("timeout"), 10, 64)
+	if err != nil || timeout <= 0 {
+		return nil, nil, fmt.Errorf("timeout must be a positive number of minutes")
+	}
+	if timeout != r.Timeout {
+		patch.Timeout = &timeout
+		changes = append(changes, change{"Timeout", fmt.Sprintf("%dm", r.Timeout), fmt.Sprintf("%dm", timeout)})
+	}
+
+	if v := strings.ToLower(m.form.Value("visibility")); v != r.Visibility {
+		valid := false
+		for _, vis := range visibilities {
+			valid = valid || v == vis
+		}
+		if !valid {
+			return nil, nil, fmt.Errorf("visibility must be one of %s", strings.Join(visibilities, ", "))
+		}
+		patch.Visibility = &v
+		changes = append(changes, change{"Visibility", r.Visibility, v})
+	}
+
+	toggle := func(key, label string, current bool, field **bool) {
+		if v := m.form.Checked(key); v != current {
+			*field = &v
+			changes = append(changes, change{label, textfmt.YesNo(current), textfmt.YesNo(v)})
+		}
+	}
+	toggle("protected", "Protected", r.Protected, &patch.Protected)
+	toggle("trusted", "Trusted", r.Trusted, &patch.Trusted)
+	toggle("cancel_pulls", "Cancel pulls", r.CancelPulls, &patch.CancelPulls)
+	toggle("cancel_push", "Cancel push", r.CancelPush, &patch.CancelPush)
+
+	return patch, changes, nil
+}
+
+// ActionDone shows the result of a change. Failed updates reopen the form
+// with the error; it reports whether the error was shown there.
+func (m *Model) ActionDone(done msg.RepoActionDoneMsg) bool {
+	m.busy = ""
+	if done.Err != nil && done.Action == msg.RepoUpdate {
+		m.editing = true
+		m.form.Reopen(done.Err.Error())
+		return true
+	}
+	if done.Repo != nil {
+		m.repo = done.Repo
+	}
+	m.refresh()
+	return false
+}
+
+func (m Model) action(a msg.RepoAction, patch *drone.RepoPatch) tea.Cmd {
+	slug := m.repo.Slug
+	return func() tea.Msg {
+		return msg.RepoActionMsg{RepoSlug: slug, Action: a, Patch: patch}
+	}
+}
+
+// refresh re-renders the settings or pending diff into the viewport
+func (m *Model) refresh() {
+	var sb strings.Builder
+	row := func(label, value string) {
+		sb.WriteString(styles.MutedStyle.Width(14).Render(label) + value + "\n")
+	}
+
+	if m.patch != nil {
+		sb.WriteString(styles.TitleStyle.Render("Apply these changes to " + m.repo.Slug + "?"))
+		sb.WriteString("\n")
+		for _, c := range m.changes {
+			row(c.label, styles.StatusFailure.Strikethrough(true).Render(c.from)+" → "+styles.StatusSuccess.Render(c.to))
+		}
+		sb.WriteString("\n" + styles.HelpStyle.Render(keymap.Join(keymap.Hint("apply", keymap.Confirm), "any other key: back to editing")))
+		m.viewport.SetContent(styles.AppStyle.Render(sb.String()))
+		return
+	}
+
+	r := m.repo
+	sb.WriteString(styles.TitleStyle.Render("Settings: " + r.Slug))
+	sb.WriteString("\n")
+	active := styles.StatusSuccess.Render("active")
+	if !r.Active {
+		active = styles.StatusFailure.Render("inactive")
+	}
+	row("Status", active)
+	row("Config path", r.Config)
+	row("Timeout", fmt.Sprintf("%dm", r.Timeout))
+	row("Visibility", r.Visibility)
+	row("Protected", textfmt.YesNo(r.Protected))
+	row("Trusted", textfmt.YesNo(r.Trusted))
+	row("Cancel pulls", textfmt.YesNo(r.CancelPulls))
+	row("Cancel push", textfmt.YesNo(r.CancelPush))
+	sb.WriteString("\n")
+
+	switch {
+	case m.busy != "":
+		sb.WriteString(styles.StatusRunning.Render(m.busy))
+	case m.confirm != nil && *m.confirm == msg.RepoDeactivate:
+		sb.WriteString(styles.StatusFailure.Render("Deactivate " + r.Slug + "? Builds will stop running. (" + keymap.Label(keymap.Confirm) + "/N)"))
+	case m.confirm != nil && *m.confirm == msg.RepoChown:
+		sb.WriteString(styles.StatusFailure.Render("Take ownership of " + r.Slug + "? Drone will use your credentials for it. (" + keymap.Label(keymap.Confirm) + "/N)"))
+	default:
+		toggle := "deactivate"
+		if !r.Active {
+			toggle = "activate"
+		}
+		sb.WriteString(styles.HelpStyle.Render(keymap.Join(
+			keymap.Help(keymap.Edit),
+			keymap.Hint(toggle, keymap.ToggleActive),
+			keymap.Help(keymap.Repair, keymap.Chown, keymap.Back),
+		)))
+	}
+	m.viewport.SetContent(styles.AppStyle.Render(sb.String()))
+}
+
+func (m Model) View() string {
+	if m.editing {
+		return m.form.View()
+	}
+	return m.viewport.View()
+}
+
+// IsFiltering reports whether keys are going to the form or a confirmation
+func (m Model) IsFiltering() bool {
+	return m.editing || m.patch != nil || m.confirm != nil
+}
+
+// Busy reports whether a change is waiting for the server
+func (m Model) Busy() bool {
+	return m.busy != ""
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.viewport.Width = w
+	m.viewport.Height = h
+	m.form.SetWidth(w)
+}
+
+// Repo returns the repo being edited
+func (m Model) Repo() *drone.Repo {
+	return m.repo
+}
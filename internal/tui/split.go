@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/logs"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drone/drone-go/drone"
+)
+
+const (
+	// splitMinWidth is the narrowest terminal the split build list is
+	// drawn on; below it the list takes the whole screen
+	splitMinWidth = 120
+	// previewDelay is how long the cursor has to rest on a build before
+	// its logs are loaded into the preview
+	previewDelay = 300 * time.Millisecond
+)
+
+// previewTickMsg fires previewDelay after the highlighted build changed
+type previewTickMsg struct {
+	gen int
+}
+
+type previewLoadedMsg struct {
+	gen   int
+	build *drone.Build
+	err   error
+}
+
+// splitActive reports whether the build list is drawn with the preview
+func (m Model) splitActive() bool {
+	return m.split && m.width >= splitMinWidth
+}
+
+func (m Model) listWidth() int {
+	if !m.splitActive() {
+		return m.width
+	}
+	return max(40, m.width*2/5)
+}
+
+func (m Model) previewWidth() int {
+	return m.width - m.listWidth() - 2 // Account for separator and gap
+}
+
+// toggleSplit switches the split layout on or off
+func (m Model) toggleSplit() (Model, tea.Cmd) {
+	m.split = !m.split
+	m.previewFocused = false
+	m.preview.SetFocused(false)
+	m = m.propagateSize()
+	m, cmd := m.schedulePreview()
+	if m.split && !m.splitActive() {
+		m, flashCmd := m.setFlash(fmt.Sprintf("Split view needs a terminal at least %d columns wide", splitMinWidth))
+		return m, tea.Batch(cmd, flashCmd)
+	}
+	return m, cmd
+}
+
+// schedulePreview loads the highlighted build into the preview once the
+// cursor has rested on it for previewDelay
+func (m Model) schedulePreview() (Model, tea.Cmd) {
+	if !m.splitActive() || m.state != stateBuildList || m.selectedRepo == nil {
+		return m, nil
+	}
+	build := m.buildList.SelectedBuild()
+	if build == nil || (build.Number == m.previewNumber && m.previewRepo == m.selectedRepo.Slug) {
+		return m, nil
+	}
+	m.previewGen++
+	m.previewNumber = build.Number
+	m.previewRepo = m.selectedRepo.Slug
+	m.previewLoaded = false
+	m.previewErr = nil
+	m.previewFocused = false
+	gen := m.previewGen
+	return m, tea.Tick(previewDelay, func(time.Time) tea.Msg {
+		return previewTickMsg{gen: gen}
+	})
+}
+
+func (m Model) loadPreviewCmd() tea.Cmd {
+	gen := m.previewGen
+	repo := m.selectedRepo
+	number := m.previewNumber
+	return func() tea.Msg {
+		build, err := m.client.GetBuild(repo.Namespace, repo.Name, int(number))
+		return previewLoadedMsg{gen: gen, build: build, err: err}
+	}
+}
+
+// updatePreview handles keys while the preview has the keyboard
+func (m Model) updatePreview(k tea.KeyMsg) (Model, tea.Cmd) {
+	if keymap.Matches(k, m.prevKey, keymap.Back, keymap.FocusPane) {
+		m.previewFocused = false
+		m.preview.SetFocused(false)
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.preview, cmd = m.preview.Update(k)
+	return m, cmd
+}
+
+// buildListView renders the build list, with the preview beside it in the
+// split layout
+func (m Model) buildListView() string {
+	if !m.splitActive() {
+		return m.buildList.View()
+	}
+	height := m.height - 1 // Account for statusbar
+
+	list := lipgloss.NewStyle().
+		Width(m.listWidth()).
+		Height(height).
+		MaxHeight(height).
+		Render(m.buildList.View())
+
+	separatorStyle := styles.BorderStyle
+	if m.previewFocused {
+		separatorStyle = styles.AccentStyle
+	}
+	separator := separatorStyle.Render(strings.TrimSuffix(strings.Repeat("│ \n", height), "\n"))
+
+	var preview string
+	switch {
+	case m.previewNumber == 0:
+		// Nothing highlighted
+	case m.previewErr != nil:
+		preview = styles.AppStyle.Render(fmt.Sprintf("Error loading build #%d: %v", m.previewNumber, m.previewErr))
+	case !m.previewLoaded:
+		preview = styles.AppStyle.Render(styles.MutedStyle.Render(fmt.Sprintf("Loading #%d...", m.previewNumber)))
+	default:
+		preview = m.preview.View()
+	}
+	preview = lipgloss.NewStyle().
+		Width(m.previewWidth()).
+		MaxWidth(m.previewWidth()).
+		MaxHeight(height).
+		Render(preview)
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, separator, preview)
+}
+
+// newPreview shows build in the preview and loads its logs
+func (m Model) newPreview(build *drone.Build) (Model, tea.Cmd) {
+	m.preview = logs.NewPreview(build, m.previewWidth(), m.height-1) // Account for statusbar
+	m.previewLoaded = true
+	return m, m.loadAllLogsCmd(build)
+}
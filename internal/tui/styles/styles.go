@@ -1,42 +1,173 @@
 package styles
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Current is the theme the styles below were built from
+var Current Theme
 
 var (
 	AppStyle = lipgloss.NewStyle().Padding(1, 2)
 
+	TitleStyle lipgloss.Style
+
+	StatusSuccess lipgloss.Style
+	StatusFailure lipgloss.Style
+	StatusRunning lipgloss.Style
+	StatusPending lipgloss.Style
+	StatusKilled  lipgloss.Style
+
+	ActiveTabStyle   lipgloss.Style
+	InactiveTabStyle lipgloss.Style
+	TabGapStyle      lipgloss.Style
+
+	SpinnerStyle lipgloss.Style
+
+	HelpStyle lipgloss.Style
+
+	// AccentStyle and HeaderStyle draw prompts, section headers and the
+	// selected row of custom lists
+	AccentStyle lipgloss.Style
+	HeaderStyle lipgloss.Style
+
+	MutedStyle     lipgloss.Style
+	BorderStyle    lipgloss.Style
+	FaintStyle     lipgloss.Style
+	HighlightStyle lipgloss.Style
+
+	// ChangedStyle marks list items that changed in the last auto-refresh
+	ChangedStyle lipgloss.Style
+
+	// BarStyle and friends draw the statusbar; PillStyle and
+	// SelectedPillStyle draw the tabs inside it
+	BarStyle          lipgloss.Style
+	BarAccentStyle    lipgloss.Style
+	BarMutedStyle     lipgloss.Style
+	PillStyle         lipgloss.Style
+	SelectedPillStyle lipgloss.Style
+)
+
+// ChangedMarker is appended to the title of recently changed list items
+var ChangedMarker string
+
+func init() {
+	Apply(Dark)
+}
+
+// Apply rebuilds every style from t. Call it before creating any view.
+func Apply(t Theme) {
+	Current = t
+
 	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("63")).
-			MarginBottom(1)
+		Bold(true).
+		Foreground(t.Accent).
+		MarginBottom(1)
 
-	StatusSuccess = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
-	StatusFailure = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
-	StatusRunning = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
-	StatusPending = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
-	StatusKilled  = lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
+	StatusSuccess = lipgloss.NewStyle().Foreground(t.Success)
+	StatusFailure = lipgloss.NewStyle().Foreground(t.Failure)
+	StatusRunning = lipgloss.NewStyle().Foreground(t.Running)
+	StatusPending = lipgloss.NewStyle().Foreground(t.Pending)
+	StatusKilled = lipgloss.NewStyle().Foreground(t.Killed)
 
 	ActiveTabStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("63")).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("63")).
-			Padding(0, 1)
+		Bold(true).
+		Foreground(t.Accent).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Accent).
+		Padding(0, 1)
 
 	InactiveTabStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("244")).
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("238")).
-				Padding(0, 1)
+		Foreground(t.Muted).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Padding(0, 1)
 
 	TabGapStyle = lipgloss.NewStyle().
-			Border(lipgloss.Border{Bottom: "─"}).
-			BorderForeground(lipgloss.Color("238"))
+		Border(lipgloss.Border{Bottom: "─"}).
+		BorderForeground(t.Border)
 
-	SpinnerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("63"))
+	SpinnerStyle = lipgloss.NewStyle().Foreground(t.Accent)
 
-	HelpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
-)
+	HelpStyle = lipgloss.NewStyle().Foreground(t.Subtle)
+
+	AccentStyle = lipgloss.NewStyle().Foreground(t.Accent)
+	HeaderStyle = AccentStyle.Bold(true)
+
+	MutedStyle = lipgloss.NewStyle().Foreground(t.Muted)
+	BorderStyle = lipgloss.NewStyle().Foreground(t.Border)
+	FaintStyle = lipgloss.NewStyle().Foreground(t.Faint)
+	HighlightStyle = lipgloss.NewStyle().Foreground(t.Highlight)
+
+	ChangedStyle = HighlightStyle.Bold(true)
+	ChangedMarker = ChangedStyle.Render("✦")
+
+	BarStyle = lipgloss.NewStyle().
+		Background(t.Surface).
+		Foreground(t.Text).
+		Padding(0, 1)
+	BarAccentStyle = BarStyle.Foreground(t.Accent).Bold(true)
+	BarMutedStyle = BarStyle.Foreground(t.Muted)
+
+	PillStyle = lipgloss.NewStyle().
+		Padding(0, 1).
+		Background(t.Surface).
+		Foreground(t.Muted)
+	SelectedPillStyle = lipgloss.NewStyle().
+		Padding(0, 1).
+		Background(t.Accent).
+		Foreground(t.AccentText).
+		Bold(true)
+
+	if t.Mono {
+		// Without color or attributes the selected tab is bracketed
+		SelectedPillStyle = lipgloss.NewStyle().
+			Border(lipgloss.Border{Left: "[", Right: "]"}, false, true)
+	}
+}
+
+// NewDelegate returns the default list delegate in the current theme
+func NewDelegate() list.DefaultDelegate {
+	t := Current
+	d := list.NewDefaultDelegate()
+	d.Styles.NormalTitle = d.Styles.NormalTitle.Foreground(t.Text)
+	d.Styles.NormalDesc = d.Styles.NormalDesc.Foreground(t.Subtle)
+	d.Styles.SelectedTitle = d.Styles.SelectedTitle.
+		Foreground(t.Accent).
+		BorderForeground(t.Accent)
+	d.Styles.SelectedDesc = d.Styles.SelectedDesc.
+		Foreground(t.Accent).
+		BorderForeground(t.Accent)
+	d.Styles.DimmedTitle = d.Styles.DimmedTitle.Foreground(t.Muted)
+	d.Styles.DimmedDesc = d.Styles.DimmedDesc.Foreground(t.Subtle)
+	return d
+}
+
+// ApplyList styles l's title, filter prompt and status bar in the current
+// theme
+func ApplyList(l *list.Model) {
+	t := Current
+	s := list.DefaultStyles()
+	s.Title = s.Title.Background(t.Accent).Foreground(t.AccentText)
+	s.FilterPrompt = s.FilterPrompt.Foreground(t.Accent)
+	s.FilterCursor = s.FilterCursor.Foreground(t.Accent)
+	s.StatusBar = s.StatusBar.Foreground(t.Subtle)
+	s.StatusEmpty = s.StatusEmpty.Foreground(t.Muted)
+	s.StatusBarActiveFilter = s.StatusBarActiveFilter.Foreground(t.Text)
+	s.StatusBarFilterCount = s.StatusBarFilterCount.Foreground(t.Muted)
+	s.NoItems = s.NoItems.Foreground(t.Muted)
+	s.ActivePaginationDot = s.ActivePaginationDot.Foreground(t.Muted)
+	s.InactivePaginationDot = s.InactivePaginationDot.Foreground(t.Border)
+	s.DividerDot = s.DividerDot.Foreground(t.Border)
+	l.Styles = s
+	l.Help.Styles.ShortKey = l.Help.Styles.ShortKey.Foreground(t.Muted)
+	l.Help.Styles.ShortDesc = l.Help.Styles.ShortDesc.Foreground(t.Subtle)
+	l.Help.Styles.ShortSeparator = l.Help.Styles.ShortSeparator.Foreground(t.Border)
+	l.Help.Styles.FullKey = l.Help.Styles.FullKey.Foreground(t.Muted)
+	l.Help.Styles.FullDesc = l.Help.Styles.FullDesc.Foreground(t.Subtle)
+	l.Help.Styles.FullSeparator = l.Help.Styles.FullSeparator.Foreground(t.Border)
+}
 
 func StatusIcon(status string) string {
 	switch status {
@@ -0,0 +1,195 @@
+package styles
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is the palette every view draws with. Each color is a role rather
+// than a hue, so a theme only has to decide what e.g. "muted" looks like on
+// its background.
+type Theme struct {
+	Name string
+
+	Accent     lipgloss.TerminalColor // titles, selection and the active tab
+	AccentText lipgloss.TerminalColor // text drawn on an accent background
+	Text       lipgloss.TerminalColor // statusbar text
+	Muted      lipgloss.TerminalColor // labels, durations and inactive tabs
+	Subtle     lipgloss.TerminalColor // help lines and descriptions
+	Border     lipgloss.TerminalColor // borders, rules and axes
+	Faint      lipgloss.TerminalColor // idle time in the timeline
+	Surface    lipgloss.TerminalColor // statusbar and tab background
+	Highlight  lipgloss.TerminalColor // changed items, favorites and SHAs
+
+	Success lipgloss.TerminalColor
+	Failure lipgloss.TerminalColor
+	Running lipgloss.TerminalColor
+	Pending lipgloss.TerminalColor
+	Killed  lipgloss.TerminalColor
+
+	// Mono marks selection with characters instead of color, since
+	// terminals without color get no bold or reverse video either
+	Mono bool
+}
+
+var (
+	Dark = Theme{
+		Name:       "dark",
+		Accent:     lipgloss.Color("63"),
+		AccentText: lipgloss.Color("231"),
+		Text:       lipgloss.Color("252"),
+		Muted:      lipgloss.Color("244"),
+		Subtle:     lipgloss.Color("241"),
+		Border:     lipgloss.Color("238"),
+		Faint:      lipgloss.Color("236"),
+		Surface:    lipgloss.Color("235"),
+		Highlight:  lipgloss.Color("220"),
+		Success:    lipgloss.Color("42"),
+		Failure:    lipgloss.Color("196"),
+		Running:    lipgloss.Color("214"),
+		Pending:    lipgloss.Color("244"),
+		Killed:     lipgloss.Color("208"),
+	}
+
+	Light = Theme{
+		Name:       "light",
+		Accent:     lipgloss.Color("25"),
+		AccentText: lipgloss.Color("231"),
+		Text:       lipgloss.Color("235"),
+		Muted:      lipgloss.Color("241"),
+		Subtle:     lipgloss.Color("244"),
+		Border:     lipgloss.Color("250"),
+		Faint:      lipgloss.Color("253"),
+		Surface:    lipgloss.Color("254"),
+		Highlight:  lipgloss.Color("130"),
+		Success:    lipgloss.Color("28"),
+		Failure:    lipgloss.Color("160"),
+		Running:    lipgloss.Color("136"),
+		Pending:    lipgloss.Color("242"),
+		Killed:     lipgloss.Color("166"),
+	}
+
+	HighContrast = Theme{
+		Name:       "high-contrast",
+		Accent:     lipgloss.Color("51"),
+		AccentText: lipgloss.Color("16"),
+		Text:       lipgloss.Color("231"),
+		Muted:      lipgloss.Color("252"),
+		Subtle:     lipgloss.Color("250"),
+		Border:     lipgloss.Color("248"),
+		Faint:      lipgloss.Color("242"),
+		Surface:    lipgloss.Color("237"),
+		Highlight:  lipgloss.Color("213"),
+		Success:    lipgloss.Color("46"),
+		Failure:    lipgloss.Color("196"),
+		Running:    lipgloss.Color("226"),
+		Pending:    lipgloss.Color("250"),
+		Killed:     lipgloss.Color("208"),
+	}
+
+	// NoColor is used when NO_COLOR is set
+	NoColor = Theme{
+		Name:       "none",
+		Accent:     lipgloss.NoColor{},
+		AccentText: lipgloss.NoColor{},
+		Text:       lipgloss.NoColor{},
+		Muted:      lipgloss.NoColor{},
+		Subtle:     lipgloss.NoColor{},
+		Border:     lipgloss.NoColor{},
+		Faint:      lipgloss.NoColor{},
+		Surface:    lipgloss.NoColor{},
+		Highlight:  lipgloss.NoColor{},
+		Success:    lipgloss.NoColor{},
+		Failure:    lipgloss.NoColor{},
+		Running:    lipgloss.NoColor{},
+		Pending:    lipgloss.NoColor{},
+		Killed:     lipgloss.NoColor{},
+		Mono:       true,
+	}
+)
+
+var builtins = []Theme{Dark, Light, HighContrast}
+
+// roles maps the color names used in custom themes to their fields
+func (t *Theme) roles() map[string]*lipgloss.TerminalColor {
+	return map[string]*lipgloss.TerminalColor{
+		"accent":      &t.Accent,
+		"accent_text": &t.AccentText,
+		"text":        &t.Text,
+		"muted":       &t.Muted,
+		"subtle":      &t.Subtle,
+		"border":      &t.Border,
+		"faint":       &t.Faint,
+		"surface":     &t.Surface,
+		"highlight":   &t.Highlight,
+		"success":     &t.Success,
+		"failure":     &t.Failure,
+		"running":     &t.Running,
+		"pending":     &t.Pending,
+		"killed":      &t.Killed,
+	}
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Resolve returns the theme called name: one of custom, or a built-in.
+// Custom themes start from the built-in named by their "base" key, dark by
+// default, and override any of the color roles with an ANSI 256 number or a
+// hex color. An empty name is the dark theme.
+func Resolve(name string, custom map[string]map[string]string) (Theme, error) {
+	if name == "" {
+		name = Dark.Name
+	}
+	colors, ok := custom[name]
+	if !ok {
+		for _, t := range builtins {
+			if t.Name == name {
+				return t, nil
+			}
+		}
+		return Theme{}, fmt.Errorf("theme: unknown theme %q", name)
+	}
+
+	base := colors["base"]
+	if base == "" {
+		base = Dark.Name
+	}
+	var t Theme
+	found := false
+	for _, b := range builtins {
+		if b.Name == base {
+			t, found = b, true
+		}
+	}
+	if !found {
+		return Theme{}, fmt.Errorf("theme %s: unknown base theme %q", name, base)
+	}
+	t.Name = name
+
+	roles := t.roles()
+	keys := make([]string, 0, len(colors))
+	for k := range colors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, role := range keys {
+		if role == "base" {
+			continue
+		}
+		field, ok := roles[role]
+		if !ok {
+			return Theme{}, fmt.Errorf("theme %s: unknown color %q", name, role)
+		}
+		value := strings.TrimSpace(colors[role])
+		if n, err := strconv.Atoi(value); !hexColor.MatchString(value) && (err != nil || n < 0 || n > 255) {
+			return Theme{}, fmt.Errorf("theme %s: %s: %q is not an ANSI color number or hex color", name, role, value)
+		}
+		*field = lipgloss.Color(value)
+	}
+	return t, nil
+}
@@ -0,0 +1,210 @@
+package tui
+
+import (
+	"sync"
+	"time"
+
+	"github.com/arch-err/drone-tui/internal/cache"
+	"github.com/arch-err/drone-tui/internal/client"
+	"github.com/arch-err/drone-tui/internal/status"
+	"github.com/arch-err/drone-tui/internal/tui/branches"
+	"github.com/arch-err/drone-tui/internal/tui/crons"
+	"github.com/arch-err/drone-tui/internal/tui/deployments"
+	"github.com/arch-err/drone-tui/internal/tui/insights"
+	"github.com/arch-err/drone-tui/internal/tui/msg"
+	"github.com/arch-err/drone-tui/internal/tui/secrets"
+	"github.com/arch-err/drone-tui/internal/tui/settings"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drone/drone-go/drone"
+)
+
+// repoTab is one of the per-repo views switchable with tab/shift+tab
+type repoTab struct {
+	state state
+	label string
+}
+
+var repoTabs = []repoTab{
+	{stateBuildList, "Builds"},
+	{stateBranchList, "Branches"},
+	{stateDeployments, "Deployments"},
+	{stateCrons, "Crons"},
+	{stateSecrets, "Secrets"},
+	{stateSettings, "Settings"},
+	{stateInsights, "Insights"},
+}
+
+// historyPages limits how many pages of builds are scanned for older
+// deployments and cron runs
+const historyPages = 3
+
+const (
+	// insightPages is how many pages of recent builds the insights tab
+	// sums up
+	insightPages = 4
+	// insightWorkers bounds how many builds are fetched at once for
+	// insights
+	insightWorkers = 8
+)
+
+// switchRepoTab moves delta tabs from the current one, wrapping around
+func (m Model) switchRepoTab(delta int) (Model, tea.Cmd) {
+	current := 0
+	for i, t := range repoTabs {
+		if t.state == m.state {
+			current = i
+			break
+		}
+	}
+	next := repoTabs[(current+delta+len(repoTabs))%len(repoTabs)]
+	return m.openRepoTab(next.state)
+}
+
+// openRepoTab shows the given per-repo view, loading its data if needed.
+// The build list is loaded on repo selection, or here when the repo was
+// opened straight into another tab.
+func (m Model) openRepoTab(s state) (Model, tea.Cmd) {
+	m.state = s
+	switch s {
+	case stateBuildList:
+		if m.buildListRepo != m.selectedRepo.Slug {
+			m.state = stateLoadingBuilds
+			m.loadingStartTime = time.Now()
+			return m, tea.Batch(m.spinner.Tick, m.loadBuildsCmd(m.selectedRepo.Namespace, m.selectedRepo.Name))
+		}
+	case stateBranchList:
+		m.branchList = branches.New(m.width, m.height-1) // Account for statusbar
+		return m, m.loadBranchesCmd()
+	case stateDeployments:
+		m.deployments = deployments.New(m.width, m.height-1) // Account for statusbar
+		return m, m.loadDeploymentsCmd()
+	case stateCrons:
+		m.crons = crons.New(m.width, m.height-1) // Account for statusbar
+		return m, m.loadCronsCmd()
+	case stateSecrets:
+		m.secrets = secrets.New(m.selectedRepo.Namespace, m.width, m.height-1) // Account for statusbar
+		return m, m.loadSecretsCmd(false)
+	case stateSettings:
+		m.settings = settings.New(m.selectedRepo, m.width, m.height-1) // Account for statusbar
+	case stateInsights:
+		m.insights = insights.New(m.width, m.height-1) // Account for statusbar
+		return m, m.loadInsightsCmd()
+	}
+	return m, nil
+}
+
+func (m Model) loadBranchesCmd() tea.Cmd {
+	repo := m.selectedRepo
+	return func() tea.Msg {
+		builds, err := m.client.ListBranches(repo.Namespace, repo.Name)
+		return msg.BranchesLoadedMsg{RepoSlug: repo.Slug, Builds: builds, Err: err}
+	}
+}
+
+func (m Model) loadDeploymentsCmd() tea.Cmd {
+	repo := m.selectedRepo
+	return func() tea.Msg {
+		latest, err := m.client.ListDeployments(repo.Namespace, repo.Name)
+		if err != nil {
+			return msg.DeploymentsLoadedMsg{RepoSlug: repo.Slug, Err: err}
+		}
+		// History is best effort: whatever recent pages hold is enough to
+		// show the previous deployment of each environment
+		var history []*drone.Build
+		for page := 1; page <= historyPages; page++ {
+			builds, err := m.client.ListBuilds(repo.Namespace, repo.Name, client.ListOptions{Page: page})
+			if err != nil || len(builds) == 0 {
+				break
+			}
+			for _, b := range builds {
+				if b.Deploy != "" {
+					history = append(history, b)
+				}
+			}
+		}
+		return msg.DeploymentsLoadedMsg{RepoSlug: repo.Slug, Latest: latest, History: history}
+	}
+}
+
+// loadInsightsCmd loads the recent finished builds of the selected repo
+// with their stages. Builds already in the local cache aren't fetched
+// again, so only the first load of a repo is slow.
+func (m Model) loadInsightsCmd() tea.Cmd {
+	repo := m.selectedRepo
+	return func() tea.Msg {
+		var listed []*drone.Build
+		for page := 1; page <= insightPages; page++ {
+			builds, err := m.client.ListBuilds(repo.Namespace, repo.Name, client.ListOptions{Page: page})
+			if err != nil {
+				return msg.InsightsLoadedMsg{RepoSlug: repo.Slug, Err: err}
+			}
+			if len(builds) == 0 {
+				break
+			}
+			listed = append(listed, builds...)
+		}
+
+		builds := cache.LoadBuilds(repo.Slug)
+		full := make([]*drone.Build, len(listed))
+		errs := make([]error, len(listed))
+		cached := 0
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, insightWorkers)
+		for i, b := range listed {
+			if !status.Finished(b.Status) {
+				continue
+			}
+			if hit, ok := builds.Get(b.Number); ok {
+				full[i] = hit
+				cached++
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				build, err := m.client.GetBuild(repo.Namespace, repo.Name, int(b.Number))
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				builds.Put(build)
+				full[i] = build
+			}()
+		}
+		wg.Wait()
+		// A cache that can't be written only makes the next load slower
+		_ = builds.Save()
+
+		// Builds that failed to load are left out, unless none loaded
+		var loaded []*drone.Build
+		var firstErr error
+		for i, b := range full {
+			if b != nil {
+				loaded = append(loaded, b)
+			} else if firstErr == nil {
+				firstErr = errs[i]
+			}
+		}
+		if len(loaded) == 0 && firstErr != nil {
+			return msg.InsightsLoadedMsg{RepoSlug: repo.Slug, Err: firstErr}
+		}
+		return msg.InsightsLoadedMsg{RepoSlug: repo.Slug, Builds: loaded, Cached: cached}
+	}
+}
+
+// renderRepoTabs renders the tab strip shown after the repo slug
+func (m Model) renderRepoTabs() string {
+	var parts []string
+	for _, t := range repoTabs {
+		style := styles.PillStyle
+		if t.state == m.state {
+			style = styles.SelectedPillStyle
+		}
+		parts = append(parts, style.Render(t.label))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+}
@@ -0,0 +1,9 @@
+package textfmt
+
+// YesNo renders a flag as "yes" or "no"
+func YesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
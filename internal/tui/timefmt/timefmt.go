@@ -0,0 +1,74 @@
+package timefmt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/drone/drone-go/drone"
+)
+
+// Duration formats d compactly, e.g. "45s", "3m07s" or "1h02m"
+func Duration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// Elapsed formats the time between two unix timestamps. A zero stop time
+// means the span is still running and is measured up to now. Returns an
+// empty string if the span has not started.
+func Elapsed(started, stopped int64) string {
+	if started == 0 {
+		return ""
+	}
+	if stopped == 0 {
+		stopped = time.Now().Unix()
+	}
+	return Duration(time.Duration(stopped-started) * time.Second)
+}
+
+// Ago formats a unix timestamp relative to now, e.g. "3 hours ago"
+func Ago(unix int64) string {
+	d := time.Since(time.Unix(unix, 0))
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		m := int(d.Minutes())
+		if m == 1 {
+			return "1 minute ago"
+		}
+		return fmt.Sprintf("%d minutes ago", m)
+	case d < 24*time.Hour:
+		h := int(d.Hours())
+		if h == 1 {
+			return "1 hour ago"
+		}
+		return fmt.Sprintf("%d hours ago", h)
+	default:
+		days := int(d.Hours() / 24)
+		if days == 1 {
+			return "1 day ago"
+		}
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
+
+// BuildTime returns when b last changed state: when it finished, else when
+// it started, else when it was created
+func BuildTime(b *drone.Build) int64 {
+	if b.Finished > 0 {
+		return b.Finished
+	}
+	if b.Started > 0 {
+		return b.Started
+	}
+	return b.Created
+}
@@ -0,0 +1,276 @@
+package timeline
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arch-err/drone-tui/internal/tui/keymap"
+	"github.com/arch-err/drone-tui/internal/tui/styles"
+	"github.com/arch-err/drone-tui/internal/tui/timefmt"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/drone/drone-go/drone"
+)
+
+const (
+	maxLabelWidth = 24
+	durationWidth = 8
+)
+
+// span is a single bar on the timeline
+type span struct {
+	label    string
+	status   string
+	started  int64
+	stopped  int64
+	isStage  bool
+	critical bool
+}
+
+type Model struct {
+	build      *drone.Build
+	viewport   viewport.Model
+	width      int
+	height     int
+	pendingKey string
+}
+
+func New(build *drone.Build, width, height int) Model {
+	m := Model{
+		build:    build,
+		viewport: viewport.New(width, height-2), // Account for separator + help line
+		width:    width,
+		height:   height,
+	}
+	m.viewport.SetContent(m.renderContent())
+	return m
+}
+
+func (m Model) Update(msgin tea.Msg) (Model, tea.Cmd) {
+	if kmsg, ok := msgin.(tea.KeyMsg); ok {
+		prev := m.pendingKey
+		m.pendingKey = ""
+
+		switch {
+		case keymap.Matches(kmsg, prev, keymap.Top):
+			m.viewport.GotoTop()
+			return m, nil
+
+		case keymap.Matches(kmsg, prev, keymap.Bottom):
+			m.viewport.GotoBottom()
+			return m, nil
+
+		case keymap.IsPrefix(kmsg, keymap.Times):
+			// Wait for the rest of a sequence such as gg
+			m.pendingKey = kmsg.String()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msgin)
+	return m, cmd
+}
+
+func (m Model) View() string {
+	help := styles.HelpStyle.Render(keymap.Join(
+		"↑/↓: scroll",
+		keymap.Hint("top/bottom", keymap.Top, keymap.Bottom),
+		"★: critical path",
+		keymap.Help(keymap.OpenInBrowser),
+		keymap.Hint("back", keymap.Timeline, keymap.Back),
+		keymap.Help(keymap.ShowHelp),
+	))
+	return lipgloss.JoinVertical(lipgloss.Left, m.viewport.View(), "", help)
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.viewport.Width = w
+	m.viewport.Height = h - 2 // Account for separator + help line
+	m.viewport.SetContent(m.renderContent())
+}
+
+func (m Model) renderContent() string {
+	spans := buildSpans(m.build)
+	if len(spans) == 0 {
+		return "No stages have started yet."
+	}
+
+	// Shared time axis across every stage and step
+	var start, end int64
+	for _, s := range spans {
+		if s.started == 0 {
+			continue
+		}
+		if start == 0 || s.started < start {
+			start = s.started
+		}
+		end = max(end, s.stopped)
+	}
+	if start == 0 {
+		return "No stages have started yet."
+	}
+	total := max(end-start, 1)
+
+	labelWidth := 0
+	for _, s := range spans {
+		labelWidth = max(labelWidth, lipgloss.Width(s.label))
+	}
+	labelWidth = min(labelWidth+2, maxLabelWidth)
+	barWidth := max(m.width-labelWidth-durationWidth-2, 10)
+
+	col := func(t int64) int {
+		return int(float64(t-start) / float64(total) * float64(barWidth))
+	}
+
+	var lines []string
+
+	// Axis with start, midpoint and end labels
+	mid := timefmt.Duration(time.Duration(total/2) * time.Second)
+	full := timefmt.Duration(time.Duration(total) * time.Second)
+	axis := []rune(strings.Repeat("─", barWidth))
+	axis[0] = '┬'
+	axis[barWidth/2] = '┬'
+	axis[barWidth-1] = '┬'
+	lines = append(lines, strings.Repeat(" ", labelWidth)+styles.BorderStyle.Render(string(axis)))
+	ticks := "0s" + strings.Repeat(" ", max(barWidth/2-2-len(mid)/2, 1)) + mid
+	ticks += strings.Repeat(" ", max(barWidth-lipgloss.Width(ticks)-len(full), 1)) + full
+	lines = append(lines, strings.Repeat(" ", labelWidth)+styles.BorderStyle.Render(ticks))
+
+	for _, s := range spans {
+		label := s.label
+		if lipgloss.Width(label) > labelWidth-1 {
+			label = string([]rune(label)[:labelWidth-2]) + "…"
+		}
+		labelCell := lipgloss.NewStyle().Width(labelWidth).Render(label)
+		if s.critical {
+			labelCell = styles.HeaderStyle.Width(labelWidth).Render(label)
+		}
+
+		if s.started == 0 {
+			lines = append(lines, labelCell+styles.FaintStyle.Render(strings.Repeat("·", barWidth)))
+			continue
+		}
+
+		from := col(s.started)
+		to := max(col(s.stopped), from+1)
+		to = min(to, barWidth)
+
+		glyph := "▬"
+		if s.isStage {
+			glyph = "█"
+		}
+		bar := styles.FaintStyle.Render(strings.Repeat("·", from)) +
+			styles.StatusStyle(s.status).Render(strings.Repeat(glyph, to-from)) +
+			styles.FaintStyle.Render(strings.Repeat("·", barWidth-to))
+
+		duration := timefmt.Elapsed(s.started, s.stopped)
+		if s.critical {
+			duration += " ★"
+		}
+		lines = append(lines, labelCell+bar+" "+styles.MutedStyle.Render(duration))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// buildSpans flattens the build into one span per stage followed by its
+// steps, marking the stages on the critical path
+func buildSpans(build *drone.Build) []span {
+	if build == nil {
+		return nil
+	}
+	now := time.Now().Unix()
+	stopOf := func(started, stopped int64) int64 {
+		if started != 0 && stopped == 0 {
+			return now
+		}
+		return stopped
+	}
+
+	critical := criticalPath(build.Stages, now)
+
+	var spans []span
+	for _, stage := range build.Stages {
+		spans = append(spans, span{
+			label:    stage.Name,
+			status:   stage.Status,
+			started:  stage.Started,
+			stopped:  stopOf(stage.Started, stage.Stopped),
+			isStage:  true,
+			critical: critical[stage],
+		})
+		for _, step := range stage.Steps {
+			spans = append(spans, span{
+				label:   "  " + step.Name,
+				status:  step.Status,
+				started: step.Started,
+				stopped: stopOf(step.Started, step.Stopped),
+			})
+		}
+	}
+	return spans
+}
+
+// criticalPath walks back from the last stage to finish, following the
+// dependency that finished last at each hop. Those are the stages that
+// determined the total build time.
+func criticalPath(stages []*drone.Stage, now int64) map[*drone.Stage]bool {
+	byName := make(map[string]*drone.Stage, len(stages))
+	for _, s := range stages {
+		byName[s.Name] = s
+	}
+	stopOf := func(s *drone.Stage) int64 {
+		if s.Started != 0 && s.Stopped == 0 {
+			return now
+		}
+		return s.Stopped
+	}
+
+	var last *drone.Stage
+	for _, s := range stages {
+		if s.Started != 0 && (last == nil || stopOf(s) > stopOf(last)) {
+			last = s
+		}
+	}
+
+	path := make(map[*drone.Stage]bool)
+	for cur := last; cur != nil && !path[cur]; {
+		path[cur] = true
+		var next *drone.Stage
+		for _, name := range cur.DependsOn {
+			if dep, ok := byName[name]; ok && dep.Started != 0 && (next == nil || stopOf(dep) > stopOf(next)) {
+				next = dep
+			}
+		}
+		cur = next
+	}
+	return path
+}
+
+// Summary returns a one-line description of the wall-clock and summed
+// stage time, useful for spotting idle gaps between stages
+func Summary(build *drone.Build) string {
+	spans := buildSpans(build)
+	var start, end, busy int64
+	for _, s := range spans {
+		if !s.isStage || s.started == 0 {
+			continue
+		}
+		if start == 0 || s.started < start {
+			start = s.started
+		}
+		end = max(end, s.stopped)
+		busy += s.stopped - s.started
+	}
+	if start == 0 {
+		return ""
+	}
+	return fmt.Sprintf("wall %s · stage time %s",
+		timefmt.Duration(time.Duration(end-start)*time.Second),
+		timefmt.Duration(time.Duration(busy)*time.Second))
+}
@@ -0,0 +1,197 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arch-err/drone-tui/internal/notify"
+	"github.com/arch-err/drone-tui/internal/status"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/drone/drone-go/drone"
+)
+
+// watch follows a single build, or the latest build on a branch, and fires
+// a notification when it reaches a terminal status
+type watch struct {
+	repo   *drone.Repo
+	number int64  // watched build, 0 for branch watches
+	branch string // watched branch, empty for build watches
+	status string // last seen status
+
+	// Branch watches only: whether the first poll has been seen, and the
+	// last build number a notification was sent for
+	primed   bool
+	notified int64
+}
+
+func (w watch) key() string {
+	if w.branch != "" {
+		return fmt.Sprintf("%s@%s", w.repo.Slug, w.branch)
+	}
+	return fmt.Sprintf("%s#%d", w.repo.Slug, w.number)
+}
+
+// observe records b as the latest poll of w and reports whether it is a
+// transition into a terminal status that should be announced
+func (w watch) observe(b *drone.Build) (watch, bool) {
+	fire := false
+	if w.branch == "" {
+		fire = status.Finished(b.Status) && !status.Finished(w.status)
+	} else {
+		// Don't announce whatever had already finished when the watch
+		// was set up
+		if !w.primed {
+			w.primed = true
+			if status.Finished(b.Status) {
+				w.notified = b.Number
+			}
+		}
+		fire = status.Finished(b.Status) && b.Number != w.notified
+		if fire {
+			w.notified = b.Number
+		}
+	}
+	w.status = b.Status
+	return w, fire
+}
+
+type watchTickMsg struct{}
+
+type watchResultMsg struct {
+	key   string
+	build *drone.Build
+	err   error
+}
+
+type flashMsg struct {
+	text string
+}
+
+type clearFlashMsg struct {
+	gen int
+}
+
+const flashDuration = 3 * time.Second
+
+// toggleWatch starts or stops watching w, starting the poller if needed
+func (m Model) toggleWatch(w watch) (Model, tea.Cmd) {
+	for i, existing := range m.watches {
+		if existing.key() == w.key() {
+			m.watches = append(m.watches[:i:i], m.watches[i+1:]...)
+			return m.setFlash("Stopped watching " + w.key())
+		}
+	}
+
+	if w.branch == "" && status.Finished(w.status) {
+		return m.setFlash(fmt.Sprintf("Build #%d already finished", w.number))
+	}
+
+	m.watches = append(m.watches, w)
+	m, flashCmd := m.setFlash("Watching " + w.key())
+	cmds := []tea.Cmd{flashCmd}
+	if !m.watchPolling {
+		m.watchPolling = true
+		cmds = append(cmds, m.pollWatchesCmd(), m.watchTickCmd())
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m Model) watchTickCmd() tea.Cmd {
+	return tea.Tick(m.activeInterval(), func(t time.Time) tea.Msg {
+		return watchTickMsg{}
+	})
+}
+
+func (m Model) pollWatchesCmd() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, w := range m.watches {
+		w := w
+		cmds = append(cmds, func() tea.Msg {
+			var build *drone.Build
+			var err error
+			if w.branch != "" {
+				build, err = m.client.GetLastBuild(w.repo.Namespace, w.repo.Name, w.branch)
+			} else {
+				build, err = m.client.GetBuild(w.repo.Namespace, w.repo.Name, int(w.number))
+			}
+			return watchResultMsg{key: w.key(), build: build, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleWatchResult records a polled status and notifies on transitions
+// into a terminal status
+func (m Model) handleWatchResult(res watchResultMsg) (Model, tea.Cmd) {
+	if res.err != nil || res.build == nil {
+		return m, nil
+	}
+	idx := -1
+	for i, w := range m.watches {
+		if w.key() == res.key {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return m, nil
+	}
+
+	b := res.build
+	w, fire := m.watches[idx].observe(b)
+
+	watches := make([]watch, 0, len(m.watches))
+	watches = append(watches, m.watches[:idx]...)
+	if w.branch != "" || !fire {
+		watches = append(watches, w)
+	}
+	watches = append(watches, m.watches[idx+1:]...)
+	m.watches = watches
+
+	if !fire {
+		return m, nil
+	}
+
+	note := notify.Notification{
+		Title:  fmt.Sprintf("%s #%d %s", w.repo.Slug, b.Number, b.Status),
+		Body:   firstLine(b.Message),
+		Status: b.Status,
+		URL:    fmt.Sprintf("%s/%s/%d", strings.TrimSuffix(m.client.ServerURL(), "/"), w.repo.Slug, b.Number),
+	}
+	m, flashCmd := m.setFlash(note.Title)
+	return m, tea.Batch(flashCmd, m.notifyCmd(note))
+}
+
+func (m Model) notifyCmd(note notify.Notification) tea.Cmd {
+	if m.notifier == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := m.notifier.Send(note); err != nil {
+			return flashMsg{text: "Notification failed: " + err.Error()}
+		}
+		return nil
+	}
+}
+
+// setFlash shows a short-lived message in the statusbar
+func (m Model) setFlash(text string) (Model, tea.Cmd) {
+	m.flash = text
+	m.flashGen++
+	gen := m.flashGen
+	if m.state == stateRepoList {
+		m.repoList.SetSize(m.width, m.repoListHeight())
+	}
+	return m, tea.Tick(flashDuration, func(t time.Time) tea.Msg {
+		return clearFlashMsg{gen: gen}
+	})
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
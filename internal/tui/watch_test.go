@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/drone/drone-go/drone"
+)
+
+func TestWatchObserveBuild(t *testing.T) {
+	w := watch{repo: &drone.Repo{Slug: "octocat/hello-world"}, number: 7, status: "pending"}
+	steps := []struct {
+		status string
+		fire   bool
+	}{
+		{"pending", false},
+		{"running", false},
+		{"failure", true},
+		// Seen again on a poll that raced the notification
+		{"failure", false},
+	}
+	for i, s := range steps {
+		var fire bool
+		w, fire = w.observe(&drone.Build{Number: 7, Status: s.status})
+		if fire != s.fire {
+			t.Errorf("poll %d (%s): fire = %v, want %v", i, s.status, fire, s.fire)
+		}
+	}
+}
+
+func TestWatchObserveBranch(t *testing.T) {
+	w := watch{repo: &drone.Repo{Slug: "octocat/hello-world"}, branch: "main"}
+	steps := []struct {
+		number int64
+		status string
+		fire   bool
+	}{
+		// Already finished when the watch started: not announced
+		{10, "success", false},
+		{10, "success", false},
+		{11, "running", false},
+		{11, "failure", true},
+		{11, "failure", false},
+		// A new build that finished between two polls
+		{12, "success", true},
+	}
+	for i, s := range steps {
+		var fire bool
+		w, fire = w.observe(&drone.Build{Number: s.number, Status: s.status})
+		if fire != s.fire {
+			t.Errorf("poll %d (#%d %s): fire = %v, want %v", i, s.number, s.status, fire, s.fire)
+		}
+	}
+}
+
+func TestWatchObserveBranchStartsRunning(t *testing.T) {
+	w := watch{repo: &drone.Repo{Slug: "octocat/hello-world"}, branch: "main"}
+	w, _ = w.observe(&drone.Build{Number: 3, Status: "running"})
+	if _, fire := w.observe(&drone.Build{Number: 3, Status: "success"}); !fire {
+		t.Error("build running when the watch started was not announced")
+	}
+}